@@ -1,12 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
+	"github.com/crelder/zet/pkg/backup"
 	"github.com/crelder/zet/pkg/export"
+	"github.com/crelder/zet/pkg/format"
 	"github.com/crelder/zet/pkg/imports"
 	"github.com/crelder/zet/pkg/index"
 	"github.com/crelder/zet/pkg/initialize"
 	"github.com/crelder/zet/pkg/parse"
+	"github.com/crelder/zet/pkg/seal"
 	"github.com/crelder/zet/pkg/transport/cli"
 	"github.com/crelder/zet/pkg/transport/fs"
 	"github.com/crelder/zet/pkg/validate"
@@ -16,7 +20,12 @@ import (
 
 func main() {
 	if r := run(); r != nil {
+		var exitErr cli.ExitError
+		if errors.As(r, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
 		log.Print(r)
+		os.Exit(cli.ExitFailure)
 	}
 }
 
@@ -44,10 +53,13 @@ func createApp() (cli.App, error) {
 	parser := parse.New()
 	repo := fs.New(wd, parser)
 	exporter := export.New(repo, repo)
-	indexer := index.New(repo, repo)
+	indexer := index.New(repo, repo, parser)
 	importer := imports.New(parser, repo, repo)
-	validator := validate.New(repo)
+	validator := validate.New(repo, repo, parser, repo)
 	initiator := initialize.New(wd)
+	formatter := format.New(repo, parser, repo, indexer)
+	sealer := seal.New(repo)
+	backuper := backup.New(wd)
 
-	return cli.NewApp(importer, exporter, indexer, validator, initiator), nil
+	return cli.NewApp(importer, exporter, indexer, validator, initiator, formatter, sealer, backuper), nil
 }
@@ -1,5 +1,10 @@
 package zet
 
+import (
+	"strings"
+	"time"
+)
+
 // Zettel holds the metadata of one thought.
 type Zettel struct {
 	Id          string
@@ -22,23 +27,240 @@ type Zettel struct {
 // it is more helpful just to use keywords as entry points
 // to own thoughts (= zettel) within the zettelkasten.
 //
-// Map assigns a topic to one or more ids (map[topic][]ids).
-type Index map[string][]string
+// Index holds the topics in the same order as in your index.txt.
+type Index []IndexEntry
+
+// IndexEntry assigns a topic to one or more ids.
+// SeeAlso holds other topics this topic refers to, like in the register of Luhmann's zettelkasten,
+// e.g. "Thermodynamics" refers to "Entropy".
+// Line is the line number of the topic in your index.txt, starting with 1.
+type IndexEntry struct {
+	Topic   string
+	Ids     []string
+	SeeAlso []string
+	Line    int
+}
+
+// Ids returns the ids of the topic and whether the topic exists in the index.
+func (i Index) Ids(topic string) ([]string, bool) {
+	for _, e := range i {
+		if e.Topic == topic {
+			return e.Ids, true
+		}
+	}
+	return nil, false
+}
 
 // Reference has a bibkey which refers to a literature reference (e.g. book, paper, etc.). E.g. "welter2011".
 // With a literature reference and the location (e.g. page number, chapter, etc.)
 // you can precisely define the source of your thought.
 type Reference struct {
+	Bibkey   string
+	Location Location
+}
+
+// Location is the position of a thought within a literature reference,
+// e.g. a page, a page range, a chapter, a section or a timestamp of a movie.
+// Raw holds the location as written in the filename, e.g. "243-245" or "ch. 3".
+//
+// Values holds the numbers of the location depending on its kind, e.g.
+// [87] for page 87, [243 245] for the pages 243-245, [3] for chapter 3, [2 3] for section 2.3 and
+// [5025] for the timestamp 01:23:45 (in seconds).
+type Location struct {
+	Raw    string
+	Kind   LocationKind
+	Values []int
+}
+
+// LocationKind is the kind of Location.
+type LocationKind string
+
+const (
+	NoLocation        LocationKind = ""
+	PageLocation      LocationKind = "page"
+	PageRangeLocation LocationKind = "pages"
+	ChapterLocation   LocationKind = "chapter"
+	SectionLocation   LocationKind = "section"
+	TimestampLocation LocationKind = "timestamp"
+	UnknownLocation   LocationKind = "unknown" // the location could not be parsed
+)
+
+// BibEntry is an entry of your references.bib, e.g. a book or a paper.
+// Type is e.g. "book" or "article" and Key is the bibkey, e.g. "welter2011".
+// Fields holds all fields of the entry with their names in lower case, e.g. "author", "title", "year",
+// "publisher" or "url".
+// File is the references file the entry is defined in, e.g. "references.bib" or "bibliography/shared.bib".
+type BibEntry struct {
+	Type   string
+	Key    string
+	Fields map[string]string
+	File   string
+}
+
+// ContextEntry declares a context of your zettel in the optional file contexts.txt, e.g. a person,
+// a place, a movie or an event.
+// Type is e.g. "Person", Name the name the context is known by, e.g. "Anna Müller", and
+// Aliases are further names used for the context in zettel filenames, e.g. "Anna".
+type ContextEntry struct {
+	Type    string
+	Name    string
+	Aliases []string
+}
+
+// Matches checks if the context of a zettel refers to this entry by its name or one of its aliases.
+// The comparison ignores the case.
+func (c ContextEntry) Matches(context string) bool {
+	if strings.EqualFold(c.Name, context) {
+		return true
+	}
+	for _, a := range c.Aliases {
+		if strings.EqualFold(a, context) {
+			return true
+		}
+	}
+	return false
+}
+
+// Config holds the settings of your zettelkasten, which are stored in the optional file config.txt.
+//
+// BibkeyPattern is a regular expression that every bibkey in a filename should match. Only bibkeys in the format
+// AUTHORYEAR in lower case are read as references from a filename, so the pattern can only narrow this format.
+// MaxIdsPerTopic is the maximum number of ids a topic of your index should have.
+// MaxTopicsPerId is the maximum number of topics of your index an id should be listed under.
+// LinkStrategy is how views like the folder 'INDEX' link to your zettel.
+// StartYear is the year you started your zettelkasten, ids dated before are reported. 0 disables the check.
+// Rules overrides the severity of rules, e.g. "index-overlap" to OffSeverity, to not report it anymore.
+// Suppressions are known inconsistencies of single zettel, that are not reported anymore.
+type Config struct {
+	BibkeyPattern  string
+	MaxIdsPerTopic int
+	MaxTopicsPerId int
+	LinkStrategy   LinkStrategy
+	StartYear      int
+	Rules          map[string]Severity
+	Suppressions   []Suppression
+}
+
+// Suppression is a known inconsistency of the rule for the zettel with the id, that you keep on purpose,
+// e.g. an old scan without keywords. Reason tells why it is kept.
+type Suppression struct {
+	Rule   string
+	Id     string
+	Reason string
+}
+
+// LinkStrategy is how views upon your zettelkasten, like the folder 'INDEX', link to your zettel.
+// Hardlinks look like the zettel itself, but only work within one filesystem and sync tools upload them
+// as additional files. Symlinks are relative, so the zettelkasten can be moved. Copies work everywhere,
+// but take space. Shortcut files are small files opening the zettel, '.url' files for Windows and
+// '.desktop' files for Linux desktops.
+type LinkStrategy string
+
+const (
+	HardlinkStrategy        LinkStrategy = "hardlink"
+	SymlinkStrategy         LinkStrategy = "symlink"
+	CopyStrategy            LinkStrategy = "copy"
+	URLShortcutStrategy     LinkStrategy = "url"
+	DesktopShortcutStrategy LinkStrategy = "desktop"
+)
+
+// Annotation is a highlight or note taken while reading a literature reference,
+// e.g. exported from Zotero or Readwise.
+// Bibkey is the citation key of the literature reference in your references.bib and
+// Location is e.g. the page of the highlight.
+// Tags are used as keywords when the annotation gets imported as a zettel.
+type Annotation struct {
+	Text     string
+	Comment  string
 	Bibkey   string
 	Location string
+	Tags     []string
+	Date     time.Time
 }
 
 // InconErr stands for inconsistency error, indicating that something is not right with your zettelkasten.
 // They are different from errors, since the programs just is aware of them but can continue functioning.
 // If you want to be sure, that zet operates correctly on your zettelkasten, make sure that
 // you don't have any inconsistencies in your zettelkasten. Run `zet validate` to get a list of inconsistencies.
+//
+// Rule names the check that found the inconsistency, e.g. "dead-link", and Severity tells if it is an error
+// or only a warning. Files lists the affected files relative to your zettelkasten, e.g. "index.txt" or the
+// filename of a zettel, and Id is the id the inconsistency is about, if there is one.
 type InconErr struct {
-	Message error
+	Message  error
+	Rule     string
+	Severity Severity
+	Files    []string
+	Id       string
+}
+
+// Severity tells how severe an inconsistency is.
+// Errors make zet operate incorrectly on your zettelkasten, warnings point out bad practice.
+type Severity string
+
+const (
+	ErrorSeverity   Severity = "error"
+	WarningSeverity Severity = "warning"
+	// OffSeverity disables a rule in your config, inconsistencies never have it.
+	OffSeverity Severity = "off"
+)
+
+// The rules of the inconsistencies, the syntax rules are used for errors while parsing files.
+const (
+	FilenameSyntaxRule   = "filename-syntax"
+	IndexSyntaxRule      = "index-syntax"
+	ReferencesSyntaxRule = "references-syntax"
+	ConfigSyntaxRule     = "config-syntax"
+	ContextsSyntaxRule   = "contexts-syntax"
+
+	DuplicateIdRule       = "duplicate-id"
+	DeadLinkRule          = "dead-link"
+	SelfLinkRule          = "self-link"
+	CycleRule             = "cycle"
+	NewerPredecessorRule  = "newer-predecessor"
+	FilenameFormatRule    = "filename-format"
+	IndexDeadLinkRule     = "index-dead-link"
+	IndexUnknownTopicRule = "index-unknown-topic"
+	IndexTopicSizeRule    = "index-topic-size"
+	IndexEntryPointRule   = "index-entry-point"
+	IndexIdSpreadRule     = "index-id-spread"
+	IndexOverlapRule      = "index-overlap"
+	MissingBibkeyRule     = "missing-bibkey"
+	UnknownLocationRule   = "unknown-location"
+	DuplicateBibkeyRule   = "duplicate-bibkey"
+	UncitedBibkeyRule     = "uncited-bibkey"
+	MissingFieldRule      = "missing-field"
+	BibkeyPatternRule     = "bibkey-pattern"
+	UnknownContextRule    = "unknown-context"
+	UnusedSuppressionRule = "unused-suppression"
+	InvalidIdDateRule     = "invalid-id-date"
+	FutureIdDateRule      = "future-id-date"
+	EarlyIdDateRule       = "early-id-date"
+	HeaderMismatchRule    = "header-mismatch"
+	ChangedZettelRule     = "changed-zettel"
+	MissingZettelRule     = "missing-zettel"
+	UnsealedZettelRule    = "unsealed-zettel"
+)
+
+// Rules lists all rules, which can be configured in your config.
+var Rules = []string{
+	FilenameSyntaxRule, IndexSyntaxRule, ReferencesSyntaxRule, ConfigSyntaxRule, ContextsSyntaxRule,
+	DuplicateIdRule, DeadLinkRule, SelfLinkRule, CycleRule, NewerPredecessorRule, FilenameFormatRule,
+	IndexDeadLinkRule, IndexUnknownTopicRule, IndexTopicSizeRule, IndexEntryPointRule, IndexIdSpreadRule,
+	IndexOverlapRule, MissingBibkeyRule, UnknownLocationRule, DuplicateBibkeyRule, UncitedBibkeyRule,
+	MissingFieldRule, BibkeyPatternRule, UnknownContextRule, UnusedSuppressionRule, InvalidIdDateRule,
+	FutureIdDateRule, EarlyIdDateRule, HeaderMismatchRule, ChangedZettelRule, MissingZettelRule, UnsealedZettelRule,
+}
+
+// IdRules lists the rules, whose inconsistencies belong to the id of a zettel and can therefore be suppressed
+// for an id in your config. Inconsistencies of the other rules, e.g. of a bibkey or a topic of the index,
+// can only be turned off. Dead links belong to the missing id, not to the zettel linking to it, so they
+// can't be suppressed for an id either.
+var IdRules = []string{
+	DuplicateIdRule, SelfLinkRule, CycleRule, NewerPredecessorRule, FilenameFormatRule,
+	IndexEntryPointRule, IndexIdSpreadRule, UnknownLocationRule, BibkeyPatternRule,
+	UnknownContextRule, InvalidIdDateRule, FutureIdDateRule, EarlyIdDateRule, HeaderMismatchRule,
+	ChangedZettelRule, MissingZettelRule, UnsealedZettelRule,
 }
 
 func (i InconErr) Error() string {
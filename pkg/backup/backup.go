@@ -0,0 +1,371 @@
+package backup
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// manifestFile holds the checksum of every other file in the archive in the format of sha256sum.
+const manifestFile = "MANIFEST.sha256"
+
+// sources are the files and folders of your zettelkasten that get backed up. Generated folders like 'INDEX'
+// or 'EXPORT' can be created again and are left out.
+var sources = []string{"zettel", "bibliography", "index.txt", "references.bib", "config.txt", "contexts.txt", "zettel.sha256"}
+
+// Backuper satisfies the zet.Backuper interface.
+// path represents the path to the directory, where your zettelkasten lies.
+type Backuper struct {
+	path string
+}
+
+func New(path string) Backuper {
+	return Backuper{
+		path: path,
+	}
+}
+
+// Backup writes a zip archive of your zettelkasten with a manifest of the checksums of all its files.
+// If dest is a folder, the archive is named after the current time, e.g. 'zettelkasten-20220527-153000.zip',
+// otherwise dest must be the name of a new .zip file. It returns the filename of the archive.
+func (b Backuper) Backup(dest string) (string, error) {
+	archive := dest
+	if info, err := os.Stat(dest); err == nil && info.IsDir() {
+		archive = filepath.Join(dest, "zettelkasten-"+time.Now().Format("20060102-150405")+".zip")
+	} else if strings.ToLower(filepath.Ext(dest)) != ".zip" {
+		return "", fmt.Errorf("backup: %q is neither a folder nor a .zip file", dest)
+	}
+	if exists(archive) {
+		return "", fmt.Errorf("backup: %q already exists", archive)
+	}
+
+	files, err := b.getFiles(archive)
+	if err != nil {
+		return "", err
+	}
+
+	// The archive is written to a temporary file first, so that an interrupted backup doesn't look complete.
+	tmp := archive + ".tmp"
+	if err := writeArchive(tmp, b.path, files); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	if err := os.Rename(tmp, archive); err != nil {
+		return "", fmt.Errorf("backup: %v", err)
+	}
+	return archive, nil
+}
+
+// getFiles returns the paths of all files to back up, relative to your zettelkasten and sorted.
+// Invisible files and the archive itself are left out.
+func (b Backuper) getFiles(archive string) ([]string, error) {
+	absArchive, err := filepath.Abs(archive)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %v", err)
+	}
+
+	var files []string
+	for _, s := range sources {
+		root := filepath.Join(b.path, s)
+		if !exists(root) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if strings.HasPrefix(d.Name(), ".") && p != root {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if abs, err := filepath.Abs(p); err == nil && (abs == absArchive || abs == absArchive+".tmp") {
+				return nil
+			}
+			rel, err := filepath.Rel(b.path, p)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("backup: %v", err)
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("backup: there are no files to back up, is this your zettelkasten directory?")
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// writeArchive writes the files, relative to the folder, and the manifest of their checksums into a zip archive.
+func writeArchive(archive, folder string, files []string) error {
+	out, err := os.Create(archive)
+	if err != nil {
+		return fmt.Errorf("backup: %v", err)
+	}
+	defer out.Close()
+
+	w := zip.NewWriter(out)
+	var manifest strings.Builder
+	for _, f := range files {
+		sum, err := addFile(w, filepath.Join(folder, filepath.FromSlash(f)), f)
+		if err != nil {
+			return fmt.Errorf("backup: %v", err)
+		}
+		fmt.Fprintf(&manifest, "%v  %v\n", sum, f)
+	}
+
+	m, err := w.Create(manifestFile)
+	if err != nil {
+		return fmt.Errorf("backup: %v", err)
+	}
+	if _, err := io.WriteString(m, manifest.String()); err != nil {
+		return fmt.Errorf("backup: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("backup: %v", err)
+	}
+	return out.Close()
+}
+
+// addFile compresses the file into the archive under the name and returns its checksum.
+// The modification time of the file is kept.
+func addFile(w *zip.Writer, file, name string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+
+	header, err := zip.FileInfoHeader(info)
+	if err != nil {
+		return "", err
+	}
+	header.Name = name
+	header.Method = zip.Deflate
+	dst, err := w.CreateHeader(header)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(dst, h), f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Verify checks every file of the archive against the manifest and returns the number of files checked.
+// A file with a different checksum, a file missing in the archive or a file missing in the manifest
+// makes the backup unusable and is returned as error.
+func (b Backuper) Verify(archive string) (int, error) {
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return 0, fmt.Errorf("backup: %v", err)
+	}
+	defer r.Close()
+
+	manifest, err := readManifest(r.File)
+	if err != nil {
+		return 0, err
+	}
+
+	var problems []string
+	found := make(map[string]bool)
+	for _, f := range r.File {
+		if f.Name == manifestFile || f.FileInfo().IsDir() {
+			continue
+		}
+		found[f.Name] = true
+		want, ok := manifest[f.Name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%q is not in the manifest", f.Name))
+			continue
+		}
+		sum, err := checksum(f)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%q can't be read: %v", f.Name, err))
+			continue
+		}
+		if sum != want {
+			problems = append(problems, fmt.Sprintf("%q has a different checksum than in the manifest", f.Name))
+		}
+	}
+	for name := range manifest {
+		if !found[name] {
+			problems = append(problems, fmt.Sprintf("%q is missing", name))
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return 0, fmt.Errorf("backup: %v is corrupted: %v", archive, strings.Join(problems, ", "))
+	}
+	return len(found), nil
+}
+
+// readManifest returns the checksums of the manifest of an archive (map[name]checksum).
+func readManifest(files []*zip.File) (map[string]string, error) {
+	for _, f := range files {
+		if f.Name != manifestFile {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("backup: %v", err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("backup: %v", err)
+		}
+
+		manifest := make(map[string]string)
+		for n, line := range strings.Split(string(content), "\n") {
+			if line == "" {
+				continue
+			}
+			i := strings.Index(line, "  ")
+			if i == -1 {
+				return nil, fmt.Errorf("backup: %v line %d: could not parse %q", manifestFile, n+1, line)
+			}
+			manifest[line[i+2:]] = line[:i]
+		}
+		return manifest, nil
+	}
+	return nil, fmt.Errorf("backup: archive has no %v, it was not created by 'zet backup'", manifestFile)
+}
+
+// Restore extracts a verified archive into dest, which must be empty or not exist yet, and checks the
+// restored files against the manifest again. It returns the number of files restored.
+func (b Backuper) Restore(archive, dest string) (int, error) {
+	if _, err := b.Verify(archive); err != nil {
+		return 0, err
+	}
+	if entries, err := os.ReadDir(dest); err == nil && len(entries) > 0 {
+		return 0, fmt.Errorf("backup: can't restore into %q, the folder is not empty", dest)
+	}
+
+	r, err := zip.OpenReader(archive)
+	if err != nil {
+		return 0, fmt.Errorf("backup: %v", err)
+	}
+	defer r.Close()
+	manifest, err := readManifest(r.File)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, f := range r.File {
+		if f.Name == manifestFile || f.FileInfo().IsDir() {
+			continue
+		}
+		if !isLocal(f.Name) {
+			return n, fmt.Errorf("backup: archive contains the invalid path %q", f.Name)
+		}
+		p := filepath.Join(dest, filepath.FromSlash(f.Name))
+		if err := extractFile(f, p); err != nil {
+			return n, fmt.Errorf("backup: %v", err)
+		}
+		sum, err := fileChecksum(p)
+		if err != nil {
+			return n, fmt.Errorf("backup: %v", err)
+		}
+		if sum != manifest[f.Name] {
+			return n, fmt.Errorf("backup: restored file %q has a different checksum than in the manifest", p)
+		}
+		n++
+	}
+	return n, nil
+}
+
+// extractFile writes the file of the archive to p and keeps its modification time.
+func extractFile(f *zip.File, p string) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return err
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+
+	out, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Chtimes(p, f.Modified, f.Modified)
+}
+
+// isLocal checks if the name of a file in the archive stays within the folder it is extracted to.
+func isLocal(name string) bool {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") || filepath.IsAbs(name) {
+		return false
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+func checksum(f *zip.File) (string, error) {
+	rc, err := f.Open()
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, rc); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func fileChecksum(p string) (string, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// exists returns false only if the file doesn't exist.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
@@ -0,0 +1,133 @@
+package backup
+
+import (
+	"archive/zip"
+	"github.com/google/go-cmp/cmp"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestBackup(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	files := map[string]string{
+		"zettel/170224a - Go.txt":       "Go",
+		"zettel/180101b - Scan.png":     "Scan",
+		"bibliography/shared.bib":       "@book{knuth1997,}",
+		"index.txt":                     "Go: 170224a",
+		"references.bib":                "@book{pike1989,}",
+		"INDEX/Go/000 170224a - Go.txt": "Go",   // generated
+		"EXPORT/keywords.txt":           "Go",   // generated
+		"zettel/.DS_Store":              "",     // invisible
+		"notes.txt":                     "Todo", // not part of your zettelkasten
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, "zettelkasten", name)
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backuper := New(filepath.Join(dir, "zettelkasten"))
+
+	// Act
+	archive, err := backuper.Backup(dir)
+	if err != nil {
+		t.Fatalf("Could not back up: %v", err)
+	}
+	n, err := backuper.Verify(archive)
+	if err != nil {
+		t.Errorf("Could not verify: %v", err)
+	}
+	restored := filepath.Join(dir, "restored")
+	n2, err := backuper.Restore(archive, restored)
+	if err != nil {
+		t.Fatalf("Could not restore: %v", err)
+	}
+
+	// Assert
+	want := []string{"bibliography/shared.bib", "index.txt", "references.bib", "zettel/170224a - Go.txt", "zettel/180101b - Scan.png"}
+	if n != len(want) || n2 != len(want) {
+		t.Errorf("verified %d and restored %d files, want %d", n, n2, len(want))
+	}
+	var got []string
+	err = filepath.Walk(restored, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel(restored, p)
+		got = append(got, filepath.ToSlash(rel))
+		content, _ := os.ReadFile(p)
+		if string(content) != files[filepath.ToSlash(rel)] {
+			t.Errorf("restored %q with content %q, want %q", rel, content, files[filepath.ToSlash(rel)])
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+
+	if _, err := backuper.Restore(archive, restored); err == nil {
+		t.Errorf("restored into a folder, that is not empty")
+	}
+	if _, err := backuper.Backup(archive); err == nil {
+		t.Errorf("overwrote an existing backup")
+	}
+}
+
+func TestVerifyCorrupted(t *testing.T) {
+	// An archive, whose zettel changed after the manifest was written.
+	archive := filepath.Join(t.TempDir(), "corrupted.zip")
+	out, err := os.Create(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := zip.NewWriter(out)
+	entries := [][2]string{
+		{"zettel/170224a - Go.txt", "Changed"},
+		{"zettel/170225b - Added.txt", "Added"},
+		{manifestFile, "e83b7fb8b0bd3e4ba9e6c3b8f0bfd1ef1b3c1b3ef01a5f8ab4bb9d4e1e5b0b84  zettel/170224a - Go.txt\n" +
+			"0000000000000000000000000000000000000000000000000000000000000000  index.txt\n"},
+	}
+	for _, e := range entries {
+		f, err := w.Create(e[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(f, e[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	out.Close()
+
+	backuper := New(t.TempDir())
+	_, err = backuper.Verify(archive)
+	if err == nil {
+		t.Fatalf("verified a corrupted archive")
+	}
+	for _, problem := range []string{
+		`"index.txt" is missing`,
+		`"zettel/170224a - Go.txt" has a different checksum than in the manifest`,
+		`"zettel/170225b - Added.txt" is not in the manifest`,
+	} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("error %q doesn't report %v", err, problem)
+		}
+	}
+	if _, err := backuper.Restore(archive, filepath.Join(t.TempDir(), "restored")); err == nil {
+		t.Errorf("restored a corrupted archive")
+	}
+}
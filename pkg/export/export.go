@@ -18,8 +18,11 @@ import (
 // wooden zettelkasten boxes. This is used for creating chains of thoughts.
 
 // PersistInfo persists some information like a list of keywords used in your zettelkasten and the number of occurrences.
+//
+// AddInfo persists further information without removing the information already persisted.
 type ExportPersister interface {
 	PersistInfo(m map[string][]string) error
+	AddInfo(m map[string][]string) error
 }
 
 // Exporter contains the application entry point for all operations regarding views upon your zettelkasten.
@@ -52,9 +55,14 @@ func (e Exporter) Export() error {
 		return err
 	}
 
+	contexts, _, err := e.Repo.GetContexts()
+	if err != nil {
+		return err
+	}
+
 	// Call method that persists all these info e.InfoPersister.PersistIndex(name, []string).
 	// Concrete Implementierung heißt CSVPersister.
-	infos, errs := getInfos(zettel, index, references)
+	infos, errs := getInfos(zettel, index, references, contexts)
 	if errs != nil {
 		fmt.Println(errs) // TODO: Better error handling
 	}
@@ -77,7 +85,7 @@ func getZettel(id string, zettel []zet.Zettel) (zet.Zettel, error) {
 	return zet.Zettel{}, fmt.Errorf("export: zettel with id %v not found", id)
 }
 
-func getInfos(zettel []zet.Zettel, index zet.Index, bibkeys []string) (map[string][]string, []error) {
+func getInfos(zettel []zet.Zettel, index zet.Index, bibkeys []string, contexts []zet.ContextEntry) (map[string][]string, []error) {
 	infos := make(map[string][]string)
 
 	ids := addFrequency(getIds(zettel))
@@ -90,7 +98,7 @@ func getInfos(zettel []zet.Zettel, index zet.Index, bibkeys []string) (map[strin
 		infos["keywords.csv"] = keywords
 	}
 
-	context := addFrequency(getContext(zettel))
+	context := addFrequency(getContext(zettel, contexts))
 	if len(context) > 0 {
 		infos["context.csv"] = context
 	}
@@ -325,11 +333,26 @@ func getKeywords(zettel []zet.Zettel) []string {
 	return keywords
 }
 
-func getContext(zettel []zet.Zettel) []string {
+// getContext returns the contexts of all zettel in the form "type;name", e.g. "Person;Anna Müller".
+// Contexts are grouped by the name declared in your contexts.txt, even if a zettel uses an alias.
+// Contexts that are not declared have no type, e.g. ";Anna".
+// Without a contexts.txt, there are no types and only the names are returned, e.g. "Anna".
+func getContext(zettel []zet.Zettel, entries []zet.ContextEntry) []string {
 	var contexts []string
 	for _, z := range zettel {
 		for _, context := range z.Context {
-			contexts = append(contexts, context)
+			if len(entries) == 0 {
+				contexts = append(contexts, context)
+				continue
+			}
+			typeAndName := ";" + context
+			for _, e := range entries {
+				if e.Matches(context) {
+					typeAndName = e.Type + ";" + e.Name
+					break
+				}
+			}
+			contexts = append(contexts, typeAndName)
 		}
 	}
 	return contexts
@@ -346,8 +369,8 @@ func getReferences(zettel []zet.Zettel) []string {
 }
 
 func isInIndex(id string, index zet.Index) bool {
-	for _, ids := range index {
-		for _, i := range ids {
+	for _, e := range index {
+		for _, i := range e.Ids {
 			if id == i {
 				return true
 			}
@@ -37,7 +37,7 @@ func TestCreateExport(t *testing.T) {
 	want := map[string]string{
 		"ids.csv":        "170224a;1\n180522a;1\n190119e;1", // TODO: Remove Häufigkeit, since an error will be listed in errors.csv
 		"keywords.csv":   "Complexity;2\nInterface;1\nPolymorphism;1\nTesting;1",
-		"context.csv":    "GopherCon;2",
+		"context.csv":    "Event;GopherCon;2",
 		"references.csv": "clausen2021;1",
 		"bibkeys.csv":    "kernighan1999;1\nsedgewick2011;1",
 		"pathDepths.csv": "190119e;2",
@@ -144,6 +144,35 @@ func TestUnindexed(t *testing.T) {
 	}
 }
 
+func TestGetContext(t *testing.T) {
+	zettel := []zet.Zettel{{Id: "170224a", Context: []string{"Anna", "GopherCon"}}}
+
+	testcases := []struct {
+		name    string
+		entries []zet.ContextEntry
+		want    []string
+	}{
+		{
+			name: "Without a contexts.txt there is no type column",
+			want: []string{"Anna", "GopherCon"},
+		},
+		{
+			name:    "Contexts not declared in the contexts.txt have an empty type",
+			entries: []zet.ContextEntry{{Type: "Event", Name: "GopherCon"}},
+			want:    []string{";Anna", "Event;GopherCon"},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := getContext(zettel, tc.entries)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
 func clearPath(path string) {
 	err := os.RemoveAll(path)
 	if err != nil {
@@ -153,7 +182,7 @@ func clearPath(path string) {
 
 type zettelkasten struct {
 	Zettel  []zet.Zettel
-	Index   []zet.Index
+	Index   zet.Index
 	Bibkeys []string
 }
 
@@ -187,7 +216,13 @@ func getExpectedJson() zettelkasten {
 			"References": [
 				{
 					"Bibkey": "clausen2021",
-					"Location": "87"
+					"Location": {
+						"Raw": "87",
+						"Kind": "page",
+						"Values": [
+							87
+						]
+					}
 				}
 			],
 			"Context": null,
@@ -209,11 +244,24 @@ func getExpectedJson() zettelkasten {
 			"Name": "190119e - Complexity - GopherCon.txt"
 		}
 	],
-	"index": {
-		"Complexity": [
-			"220122a"
-		]
-	},
+	"index": [
+		{
+			"Topic": "Complexity",
+			"Ids": [
+				"220122a"
+			],
+			"SeeAlso": null,
+			"Line": 1
+		},
+		{
+			"Topic": "Entropy",
+			"Ids": null,
+			"SeeAlso": [
+				"Complexity"
+			],
+			"Line": 2
+		}
+	],
 	"bibkeys": [
 		"kernighan1999",
 		"sedgewick2011"
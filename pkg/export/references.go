@@ -0,0 +1,297 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/crelder/zet"
+	"sort"
+	"strings"
+)
+
+// cslTypes maps BibTeX entry types to CSL types. All other types become a "document".
+var cslTypes = map[string]string{
+	"article":       "article-journal",
+	"book":          "book",
+	"booklet":       "pamphlet",
+	"inbook":        "chapter",
+	"incollection":  "chapter",
+	"inproceedings": "paper-conference",
+	"conference":    "paper-conference",
+	"manual":        "report",
+	"mastersthesis": "thesis",
+	"phdthesis":     "thesis",
+	"thesis":        "thesis",
+	"online":        "webpage",
+	"proceedings":   "book",
+	"techreport":    "report",
+	"report":        "report",
+	"unpublished":   "manuscript",
+}
+
+// ExportReferences writes the literature references cited by a selection of zettel into the folder 'EXPORT':
+//   - cited.bib contains the cited entries of your references.bib and
+//   - cited.json contains the same entries as CSL-JSON, e.g. for Pandoc.
+//
+// Every entry holds the ids of the zettel citing it, in the field 'zettel' of cited.bib and
+// in the field 'note' of cited.json.
+//
+// The selection is either empty (all zettel), an id (the zettel and its chain of Folgezettel) or
+// a topic of your index (the chains of all ids of that topic).
+func (e Exporter) ExportReferences(selection string) error {
+	zettel, _, err := e.Repo.GetZettel()
+	if err != nil {
+		return fmt.Errorf("error exporting references: %w", err)
+	}
+	index, _, err := e.Repo.GetIndex()
+	if err != nil {
+		return fmt.Errorf("error exporting references: %w", err)
+	}
+	references, _, err := e.Repo.GetReferences()
+	if err != nil {
+		return fmt.Errorf("error exporting references: %w", err)
+	}
+
+	selected, err := selectZettel(selection, zettel, index)
+	if err != nil {
+		return err
+	}
+	entries, citingIds := getCited(selected, references)
+
+	j, err := getCSLJson(entries, citingIds)
+	if err != nil {
+		return fmt.Errorf("error exporting references: %w", err)
+	}
+
+	return e.Persister.AddInfo(map[string][]string{
+		"cited.bib":  getBib(entries, citingIds),
+		"cited.json": j,
+	})
+}
+
+// selectZettel returns all zettel, the chain of a zettel with the given id or the chains of an index topic.
+func selectZettel(selection string, zettel []zet.Zettel, index zet.Index) ([]zet.Zettel, error) {
+	if selection == "" {
+		return zettel, nil
+	}
+
+	m := make(map[string]zet.Zettel)
+	for _, z := range zettel {
+		m[z.Id] = z
+	}
+
+	var startIds []string
+	if _, ok := m[selection]; ok {
+		startIds = []string{selection}
+	} else {
+		ids, ok := index.Ids(selection)
+		if !ok {
+			return nil, fmt.Errorf("export: %q is neither the id of a zettel nor a topic of the index", selection)
+		}
+		startIds = ids
+	}
+
+	var selected []zet.Zettel
+	visited := make(map[string]bool)
+	var addChain func(id string)
+	addChain = func(id string) {
+		z, ok := m[id]
+		if !ok || visited[id] {
+			return
+		}
+		visited[id] = true
+		selected = append(selected, z)
+		for _, f := range z.Folgezettel {
+			addChain(f)
+		}
+	}
+	for _, id := range startIds {
+		if _, ok := m[id]; !ok {
+			return nil, fmt.Errorf("export: zettel with id %v not found", id)
+		}
+		addChain(id)
+	}
+	return selected, nil
+}
+
+// getCited returns the entries of your references.bib cited by the zettel sorted by their bibkey and
+// for every bibkey the sorted ids of the zettel citing it.
+// Bibkeys without an entry in your references.bib are left out; 'zet validate' lists them.
+func getCited(zettel []zet.Zettel, references []zet.BibEntry) ([]zet.BibEntry, map[string][]string) {
+	citingIds := make(map[string][]string)
+	for _, z := range zettel {
+		for _, r := range z.References {
+			citingIds[r.Bibkey] = append(citingIds[r.Bibkey], z.Id)
+		}
+	}
+
+	var entries []zet.BibEntry
+	added := make(map[string]bool)
+	for _, r := range references {
+		if _, ok := citingIds[r.Key]; !ok || added[r.Key] {
+			continue
+		}
+		added[r.Key] = true
+		entries = append(entries, r)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+
+	for bibkey, ids := range citingIds {
+		sort.Strings(ids)
+		citingIds[bibkey] = removeDuplicates(ids)
+	}
+	return entries, citingIds
+}
+
+// getBib returns the entries in the BibTeX format with the additional field 'zettel', e.g.
+//
+//	@book{welter2011,
+//		author = {Welter, Thomas},
+//		year = {2011},
+//		zettel = {170224a, 180522a}}
+func getBib(entries []zet.BibEntry, citingIds map[string][]string) []string {
+	var lines []string
+	for n, e := range entries {
+		if n > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "@"+e.Type+"{"+e.Key+",")
+
+		fields := make(map[string]string)
+		for name, value := range e.Fields {
+			fields[name] = value
+		}
+		fields["zettel"] = strings.Join(citingIds[e.Key], ", ")
+
+		var names []string
+		for name := range fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for i, name := range names {
+			line := "\t" + name + " = {" + fields[name] + "}"
+			if i < len(names)-1 {
+				line += ","
+			} else {
+				line += "}"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+type cslName struct {
+	Family  string `json:"family,omitempty"`
+	Given   string `json:"given,omitempty"`
+	Literal string `json:"literal,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]string `json:"date-parts"`
+}
+
+type cslItem struct {
+	Id             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title,omitempty"`
+	Author         []cslName `json:"author,omitempty"`
+	Editor         []cslName `json:"editor,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	Volume         string    `json:"volume,omitempty"`
+	Issue          string    `json:"issue,omitempty"`
+	Page           string    `json:"page,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	ISBN           string    `json:"ISBN,omitempty"`
+	Note           string    `json:"note,omitempty"`
+}
+
+// getCSLJson returns the entries as CSL-JSON, the format of citeproc processors like Pandoc.
+func getCSLJson(entries []zet.BibEntry, citingIds map[string][]string) ([]string, error) {
+	items := []cslItem{}
+	for _, e := range entries {
+		f := func(name string) string {
+			return stripBraces(e.Fields[name])
+		}
+		item := cslItem{
+			Id:             e.Key,
+			Type:           cslTypes[e.Type],
+			Title:          f("title"),
+			Author:         getCSLNames(f("author")),
+			Editor:         getCSLNames(f("editor")),
+			ContainerTitle: firstNonEmpty(f("journal"), f("journaltitle"), f("booktitle")),
+			Publisher:      firstNonEmpty(f("publisher"), f("school"), f("institution")),
+			Volume:         f("volume"),
+			Issue:          f("number"),
+			Page:           strings.ReplaceAll(f("pages"), "--", "-"),
+			DOI:            f("doi"),
+			URL:            f("url"),
+			ISBN:           f("isbn"),
+			Note:           "zettel: " + strings.Join(citingIds[e.Key], ", "),
+		}
+		if item.Type == "" {
+			item.Type = "document"
+		}
+		if year := firstNonEmpty(f("year"), f("date")); year != "" {
+			item.Issued = &cslDate{DateParts: [][]string{strings.Split(year, "-")}}
+		}
+		items = append(items, item)
+	}
+
+	j, err := json.MarshalIndent(items, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Split(string(j), "\n"), nil
+}
+
+// getCSLNames splits a BibTeX name list like "Kernighan, Brian W. and Rob Pike" into names.
+func getCSLNames(s string) []cslName {
+	if s == "" {
+		return nil
+	}
+	var names []cslName
+	for _, name := range strings.Split(s, " and ") {
+		name = strings.TrimSpace(name)
+		if i := strings.Index(name, ","); i != -1 {
+			names = append(names, cslName{Family: strings.TrimSpace(name[:i]), Given: strings.TrimSpace(name[i+1:])})
+			continue
+		}
+		parts := strings.Fields(name)
+		if len(parts) == 1 {
+			names = append(names, cslName{Literal: name})
+			continue
+		}
+		names = append(names, cslName{Family: parts[len(parts)-1], Given: strings.Join(parts[:len(parts)-1], " ")})
+	}
+	return names
+}
+
+// stripBraces removes the braces BibTeX uses for protecting e.g. capital letters, like in "{G}o".
+func stripBraces(s string) string {
+	return strings.NewReplacer("{", "", "}", "").Replace(s)
+}
+
+func firstNonEmpty(s ...string) string {
+	for _, e := range s {
+		if e != "" {
+			return e
+		}
+	}
+	return ""
+}
+
+func removeDuplicates(sorted []string) []string {
+	var result []string
+	for i, s := range sorted {
+		if i > 0 && s == sorted[i-1] {
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
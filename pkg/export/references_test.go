@@ -0,0 +1,159 @@
+package export
+
+import (
+	"github.com/crelder/zet/pkg/parse"
+	"github.com/crelder/zet/pkg/transport/fs"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path"
+	"testing"
+)
+
+func TestExportReferences(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = wd + "/testdata/zettelkasten2"
+	parser := parse.New()
+	r := fs.New(pathTestRepo, parser)
+	exporter := New(r, r)
+
+	var tcs = []struct {
+		name      string
+		selection string
+		bib       string
+		json      string
+	}{
+		{
+			name:      "All zettel",
+			selection: "",
+			bib: `@book{clausen2021,
+	author = {Clausen, Lennart},
+	publisher = {Springer},
+	title = {{S}oftware Testing},
+	year = {2021},
+	zettel = {190119d, 220115p}}
+
+@article{kernighan2016,
+	author = {Brian W. Kernighan},
+	journal = {Communications of the ACM},
+	pages = {150--160},
+	title = {Programming},
+	year = {2016},
+	zettel = {210328obj}}`,
+			json: `[
+	{
+		"id": "clausen2021",
+		"type": "book",
+		"title": "Software Testing",
+		"author": [
+			{
+				"family": "Clausen",
+				"given": "Lennart"
+			}
+		],
+		"issued": {
+			"date-parts": [
+				[
+					"2021"
+				]
+			]
+		},
+		"publisher": "Springer",
+		"note": "zettel: 190119d, 220115p"
+	},
+	{
+		"id": "kernighan2016",
+		"type": "article-journal",
+		"title": "Programming",
+		"author": [
+			{
+				"family": "Kernighan",
+				"given": "Brian W."
+			}
+		],
+		"issued": {
+			"date-parts": [
+				[
+					"2016"
+				]
+			]
+		},
+		"container-title": "Communications of the ACM",
+		"page": "150-160",
+		"note": "zettel: 210328obj"
+	}
+]`,
+		},
+		{
+			// 220115p also cites clausen2021, but is not part of the chain starting at 190119d.
+			name:      "Chain",
+			selection: "190119d",
+			bib: `@book{clausen2021,
+	author = {Clausen, Lennart},
+	publisher = {Springer},
+	title = {{S}oftware Testing},
+	year = {2021},
+	zettel = {190119d}}`,
+		},
+		{
+			// Ids can have up to three letters.
+			name:      "Chain of an id with several letters",
+			selection: "210328obj",
+			bib: `@article{kernighan2016,
+	author = {Brian W. Kernighan},
+	journal = {Communications of the ACM},
+	pages = {150--160},
+	title = {Programming},
+	year = {2016},
+	zettel = {210328obj}}`,
+		},
+		{
+			// The chain of the topic consists of 220122a and 191212b, which cite nothing.
+			name:      "Topic",
+			selection: "Komplexität",
+			bib:       "",
+			json:      "[]",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// Act
+			err := exporter.ExportReferences(tc.selection)
+			if err != nil {
+				t.Errorf("could not export references: %v", err)
+			}
+
+			// Assert
+			bib, err := os.ReadFile(path.Join(pathTestRepo, "EXPORT", "cited.bib"))
+			if err != nil {
+				t.Errorf("error reading cited.bib: %v", err)
+			}
+			if diff := cmp.Diff(tc.bib, string(bib)); diff != "" {
+				t.Errorf(diff)
+			}
+
+			if tc.json == "" {
+				return
+			}
+			j, err := os.ReadFile(path.Join(pathTestRepo, "EXPORT", "cited.json"))
+			if err != nil {
+				t.Errorf("error reading cited.json: %v", err)
+			}
+			if diff := cmp.Diff(tc.json, string(j)); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+
+	for _, selection := range []string{"Unknown topic", "991231z"} {
+		if err := exporter.ExportReferences(selection); err == nil {
+			t.Errorf("expected an error for %q, which is neither the id of a zettel nor a topic", selection)
+		}
+	}
+
+	clearPath(pathTestRepo + "/EXPORT")
+}
@@ -0,0 +1,96 @@
+package format
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"sort"
+	"strings"
+)
+
+// Formatter renames all zettel to their canonical filename.
+// Formatter satisfies the zet.Formatter interface.
+type Formatter struct {
+	Repo    zet.Repo
+	Parser  zet.Parser
+	Renamer Renamer
+	Views   Views
+}
+
+// Renamer renames a zettel file in the folder 'zettel', together with the transcription of a scan.
+type Renamer interface {
+	RenameZettel(oldName, newName string) error
+}
+
+// Views updates the folder 'INDEX', so that its links carry the new filenames.
+type Views interface {
+	Create(dryRun bool) ([]string, error)
+}
+
+func New(r zet.Repo, p zet.Parser, rn Renamer, v Views) Formatter {
+	return Formatter{
+		Repo:    r,
+		Parser:  p,
+		Renamer: rn,
+		Views:   v,
+	}
+}
+
+// Format renames every zettel, whose filename differs from the filename rendered from its parsed metadata,
+// e.g. '170224a - Go,Testing .txt' becomes '170224a - Go, Testing.txt'. Afterwards, the folder 'INDEX' is updated.
+// It returns the renames in the form "old name -> new name". With check, the zettel are not renamed.
+//
+// A filename is not formatted, if formatting would drop some of its text, e.g. since it couldn't be parsed
+// completely; 'zet validate' reports such filenames.
+func (f Formatter) Format(check bool) ([]string, error) {
+	zettel, _, err := f.Repo.GetZettel()
+	if err != nil {
+		return nil, fmt.Errorf("error formatting filenames: %w", err)
+	}
+
+	renames := make(map[string]string)
+	taken := make(map[string]bool)
+	for _, z := range zettel {
+		taken[z.Name] = true
+	}
+	for _, z := range zettel {
+		name, err := f.Parser.Format(z)
+		if err != nil || name == z.Name || !sameText(z.Name, name) {
+			continue
+		}
+		if taken[name] {
+			return nil, fmt.Errorf("format: can't rename %q to %q, the file already exists", z.Name, name)
+		}
+		taken[name] = true
+		renames[z.Name] = name
+	}
+
+	var result []string
+	for old, name := range renames {
+		result = append(result, old+" -> "+name)
+	}
+	sort.Strings(result)
+	if check || len(renames) == 0 {
+		return result, nil
+	}
+
+	for old, name := range renames {
+		if err := f.Renamer.RenameZettel(old, name); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := f.Views.Create(false); err != nil {
+		return nil, fmt.Errorf("error updating the index after formatting filenames: %w", err)
+	}
+	return result, nil
+}
+
+// sameText checks if both filenames consist of the same text apart from spaces, separators and their order.
+func sameText(a, b string) bool {
+	return normalize(a) == normalize(b)
+}
+
+func normalize(s string) string {
+	r := strings.Split(strings.NewReplacer(" ", "", ",", "", "-", "").Replace(s), "")
+	sort.Strings(r)
+	return strings.Join(r, "")
+}
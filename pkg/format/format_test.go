@@ -0,0 +1,86 @@
+package format
+
+import (
+	"github.com/crelder/zet/pkg/index"
+	"github.com/crelder/zet/pkg/parse"
+	"github.com/crelder/zet/pkg/transport/fs"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.Mkdir(dir+"/zettel", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		"170224a - Go,Testing .txt",
+		"180101b - Scan,  Handwriting - 170224a.png",
+		"180101b - Scan,  Handwriting - 170224a.txt", // the transcription of the scan
+		"190101c - Formatted.txt",
+		"190102d - Keyword - Context - no id.txt", // formatting would drop "no id"
+	}
+	for _, f := range files {
+		if err := os.WriteFile(dir+"/zettel/"+f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(dir+"/index.txt", []byte("Go: 170224a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := parse.New()
+	repo := fs.New(dir, parser)
+	indexer := index.New(repo, repo, parser)
+	formatter := New(repo, parser, repo, indexer)
+	if _, err := indexer.Create(false); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"170224a - Go,Testing .txt -> 170224a - Go, Testing.txt",
+		"180101b - Scan,  Handwriting - 170224a.png -> 180101b - Scan, Handwriting - 170224a.png",
+	}
+
+	// Act & Assert
+	// A check only lists the renames.
+	got, err := formatter.Format(true)
+	if err != nil {
+		t.Errorf("Could not check filenames: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	if _, err := os.Stat(dir + "/zettel/" + files[0]); err != nil {
+		t.Errorf("check renamed a file: %v", err)
+	}
+
+	got, err = formatter.Format(false)
+	if err != nil {
+		t.Errorf("Could not format filenames: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	for _, f := range []string{
+		"zettel/170224a - Go, Testing.txt",
+		"zettel/180101b - Scan, Handwriting - 170224a.png",
+		"zettel/180101b - Scan, Handwriting - 170224a.txt",
+		"zettel/190102d - Keyword - Context - no id.txt",
+		"INDEX/Go/170224a/000 170224a - Go, Testing.txt",
+	} {
+		if _, err := os.Stat(dir + "/" + f); err != nil {
+			t.Errorf("file was not renamed: %v", err)
+		}
+	}
+
+	// Formatted filenames stay the same.
+	got, err = formatter.Format(true)
+	if err != nil {
+		t.Errorf("Could not check filenames: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Got renames %v, wanted none", got)
+	}
+}
@@ -1,7 +1,10 @@
 package imports
 
 import (
+	"fmt"
 	"github.com/crelder/zet"
+	"path/filepath"
+	"strings"
 )
 
 // Importer provides functionality for importing new text zettel.
@@ -23,8 +26,11 @@ func New(p zet.Parser, r Reader, repo zet.Repo) Importer {
 // a valid filename with all the zettel's metadata and a unique id.
 //
 // GetContents takes a path to a folder with textfiles and returns their contents.
+//
+// GetFiles takes a path to a folder or a file and returns the raw data of all files as map[filename]data.
 type Reader interface {
 	GetContents(uri string) ([]string, error)
+	GetFiles(uri string) (map[string][]byte, error)
 }
 
 // Import reads all the zettel contents from the parameter path.
@@ -39,18 +45,65 @@ func (i Importer) Import(path string) (int, error) {
 		return 0, err
 	}
 
+	return i.save(contents)
+}
+
+// ImportAnnotations reads a CSV or JSON export of annotations (e.g. from Zotero or Readwise) from the parameter path.
+// The format is determined by the file extension.
+// Every annotation becomes a zettel: its tags become the keywords and its citation key and location
+// become the reference. The citation key must exist in your references.bib.
+//
+// In case of success ImportAnnotations returns the number of zettel created and a nil error.
+// In case of an error ImportAnnotations returns 0 (no zettel are created) and the error.
+func (i Importer) ImportAnnotations(path string) (int, error) {
+	contents, err := i.reader.GetContents(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(contents) != 1 {
+		return 0, fmt.Errorf("imports: %q should be a single CSV or JSON file", path)
+	}
+
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	annotations, err2 := i.parser.Annotations(contents[0], format)
+	if err2 != nil {
+		return 0, err2
+	}
+
+	bibkeys, err3 := i.repo.GetBibkeys()
+	if err3 != nil {
+		return 0, err3
+	}
+
+	var zettelContents []string
+	for n, a := range annotations {
+		if !contains(bibkeys, a.Bibkey) {
+			return 0, fmt.Errorf("imports: annotation %d: citation key %q not found in references.bib", n+1, a.Bibkey)
+		}
+		if len(a.Tags) == 0 {
+			return 0, fmt.Errorf("imports: annotation %d: no tags provided, but at least one tag is needed as keyword", n+1)
+		}
+		zettelContents = append(zettelContents, toContent(a))
+	}
+
+	return i.save(zettelContents)
+}
+
+// save generates for every zettel content a valid filename containing all the zettel's metadata and
+// a unique id and persists it.
+func (i Importer) save(contents []string) (int, error) {
 	zettel, _, err2 := i.repo.GetZettel()
 	if err2 != nil {
 		return 0, err2
 	}
 
-	zettelFiles := make(map[string]string)
+	zettelFiles := make(map[string][]byte)
 	for _, content := range contents {
 		filename, err3 := i.parser.Content(content, zettel)
 		if err3 != nil {
 			return 0, err3
 		}
-		zettelFiles[filename] = content
+		zettelFiles[filename] = []byte(content)
 
 		// Make sure that a following import is not using the same id as this zettel.
 		z, err4 := i.parser.Filename(filename)
@@ -69,3 +122,40 @@ func (i Importer) Import(path string) (int, error) {
 
 	return n, nil
 }
+
+// toContent converts an annotation into the content of a text zettel.
+// The header holds the tags as keywords, the date and the reference. The highlighted text is quoted.
+func toContent(a zet.Annotation) string {
+	reference := a.Bibkey
+	if a.Location != "" {
+		reference += " " + a.Location
+	}
+	header := []string{
+		strings.Join(a.Tags, ", "),
+		a.Date.Format("2.1.2006"),
+		reference,
+	}
+
+	var body []string
+	if a.Text != "" {
+		var quoted []string
+		for _, line := range strings.Split(a.Text, "\n") {
+			quoted = append(quoted, "> "+line)
+		}
+		body = append(body, strings.Join(quoted, "\n"))
+	}
+	if a.Comment != "" {
+		body = append(body, a.Comment)
+	}
+
+	return strings.Join(header, "\n") + "\n\n" + strings.Join(body, "\n\n")
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -3,8 +3,10 @@ package imports
 import (
 	"errors"
 	"fmt"
+	"github.com/crelder/zet"
 	"github.com/crelder/zet/pkg/parse"
 	fsRepo "github.com/crelder/zet/pkg/transport/fs"
+	"github.com/google/go-cmp/cmp"
 	"io/fs"
 	"os"
 	"path"
@@ -146,3 +148,257 @@ Some thought...`
 		}
 	}
 }
+
+func TestImportAnnotations(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = path.Join(wd, "testdata", "zettelkasten3")
+	p := parse.New()
+	repo := fsRepo.New(pathTestRepo, p)
+	importer := New(p, repo, repo)
+
+	// Rebuild a clean state of the zettel folder
+	err2 := os.RemoveAll(path.Join(pathTestRepo, "zettel"))
+	if err2 != nil {
+		t.Errorf("could not remove zettel folder for recreating it")
+	}
+	err = os.MkdirAll(pathTestRepo+"/zettel", 0755)
+	if err != nil {
+		t.Errorf("could not create zettel folder: %v", err)
+	}
+
+	// Act
+	n, err := importer.ImportAnnotations("./testdata/annotations/annotations.csv")
+
+	// Assert
+	if err != nil {
+		t.Errorf("error importing annotations: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Imported %v files, should have imported 2", n)
+	}
+
+	var testcases = []string{
+		// The tags of an annotation become the keywords, the citation key and page become the reference.
+		"210811c - Complexity, Entropy - welter2011 243.txt",
+		"210811d - Design - welter2011 245.txt",
+	}
+	pathZettelTestRepo := pathTestRepo + "/zettel/"
+	for _, tc := range testcases {
+		if _, err := os.Stat(pathZettelTestRepo + tc); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("File was not created: %v", tc)
+			} else {
+				t.Errorf("error occurred: %v", err)
+			}
+		}
+	}
+
+	// An annotation with a citation key that is not in the references.bib should not get imported.
+	n, err = importer.ImportAnnotations("./testdata/annotations/unknown_bibkey.json")
+	wantErr := "imports: annotation 1: citation key \"unknown2020\" not found in references.bib"
+	if err == nil || err.Error() != wantErr {
+		t.Errorf("Expected error %q, got %v", wantErr, err)
+	}
+	if n != 0 {
+		t.Errorf("Imported %v files, should have imported 0", n)
+	}
+}
+
+func TestImportScans(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = path.Join(wd, "testdata", "zettelkasten3")
+	p := parse.New()
+	repo := fsRepo.New(pathTestRepo, p)
+	importer := New(p, repo, repo)
+
+	// Rebuild a clean state of the zettel folder
+	err2 := os.RemoveAll(path.Join(pathTestRepo, "zettel"))
+	if err2 != nil {
+		t.Errorf("could not remove zettel folder for recreating it")
+	}
+	err = os.MkdirAll(pathTestRepo+"/zettel", 0755)
+	if err != nil {
+		t.Errorf("could not create zettel folder: %v", err)
+	}
+
+	// Act
+	n, err := importer.ImportScans("./testdata/scans")
+
+	// Assert
+	if err != nil {
+		t.Errorf("error importing scans: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("Imported %v zettel, should have imported 2", n)
+	}
+
+	var testcases = []string{
+		// The metadata of this scan comes from the sidecar textfile.
+		"210811c - Complexity, Entropy - welter2011 243.png",
+		// The sidecar textfile contains a transcription, which is saved under the same id.
+		"210811c - Complexity, Entropy - welter2011 243.txt",
+		// The metadata of this scan comes from the manifest.csv.
+		"210811d - Design - welter2011 245.pdf",
+	}
+	pathZettelTestRepo := pathTestRepo + "/zettel/"
+	for _, tc := range testcases {
+		if _, err := os.Stat(pathZettelTestRepo + tc); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("File was not created: %v", tc)
+			} else {
+				t.Errorf("error occurred: %v", err)
+			}
+		}
+	}
+
+	// The transcription belongs to the zettel of the scan and is not a zettel on its own.
+	zettel, _, err := repo.GetZettel()
+	if err != nil {
+		t.Errorf("error getting zettel: %v", err)
+	}
+	if len(zettel) != 2 {
+		t.Errorf("Got %v zettel, should be 2", len(zettel))
+	}
+}
+
+func TestImportReferences(t *testing.T) {
+	// Arrange
+	pathTestRepo := t.TempDir()
+	err := os.WriteFile(path.Join(pathTestRepo, "references.bib"), []byte("@book{welter2011,\n\tauthor = {Welter, Rudolf},\n\ttitle = {Komplexität},\n\tyear = {2011}}"), 0644)
+	if err != nil {
+		t.Errorf("could not create references.bib: %v", err)
+	}
+	p := parse.New()
+	repo := fsRepo.New(pathTestRepo, p)
+	importer := New(p, repo, repo)
+
+	// Act
+	n, err := importer.ImportReferences("./testdata/references/references.ris")
+	if err != nil {
+		t.Errorf("error importing references: %v", err)
+	}
+	n2, err := importer.ImportReferences("./testdata/references/references.xml")
+	if err != nil {
+		t.Errorf("error importing references: %v", err)
+	}
+
+	// Assert
+	if n != 2 || n2 != 1 {
+		t.Errorf("Imported %v and %v references, should have imported 2 and 1", n, n2)
+	}
+
+	// The existing entry stays untouched and the new bibkeys don't collide with existing ones.
+	want := `@book{welter2011,
+	author = {Welter, Rudolf},
+	title = {Komplexität},
+	year = {2011}}
+
+@article{welter2011a,
+	author = {Welter, Rudolf},
+	journal = {Zeitschrift für Systemtheorie},
+	number = {3},
+	pages = {243--260},
+	title = {Komplexität und Entropie},
+	volume = {12},
+	year = {2011}}
+
+@incollection{mueller2020,
+	author = {Müller, Anna and Rob Pike},
+	booktitle = {Essays on Software},
+	editor = {Kernighan, Brian W.},
+	keywords = {Design, Simplicity},
+	publisher = {Addison-Wesley},
+	title = {Simplicity},
+	year = {2020}}
+
+@book{welter2011b,
+	author = {Welter, Rudolf},
+	publisher = {Springer},
+	title = {Systeme},
+	url = {https://example.org/systeme},
+	year = {2011}}
+`
+	got, err := os.ReadFile(path.Join(pathTestRepo, "references.bib"))
+	if err != nil {
+		t.Errorf("could not read references.bib: %v", err)
+	}
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestGetBibkey(t *testing.T) {
+	e := zet.BibEntry{Type: "book", Fields: map[string]string{"author": "Welter, Rudolf", "year": "2011"}}
+	taken := map[string]bool{"welter2011": true}
+	for letter := 'a'; letter < 'z'; letter++ {
+		taken["welter2011"+string(letter)] = true
+	}
+
+	key, err := getBibkey(e, taken)
+	if err != nil || key != "welter2011z" {
+		t.Errorf("got bibkey %q and error %v, want welter2011z", key, err)
+	}
+
+	// Without a free letter, no bibkey that is already taken is returned.
+	taken["welter2011z"] = true
+	if key, err := getBibkey(e, taken); err == nil {
+		t.Errorf("got the taken bibkey %q, want an error", key)
+	}
+}
+
+func TestGetBibkeyFallback(t *testing.T) {
+	tcs := []struct {
+		name   string
+		fields map[string]string
+		want   string
+		err    bool
+	}{
+		{name: "Author", fields: map[string]string{"author": "Müller, Anna", "year": "2020"}, want: "mueller2020"},
+		{name: "Author without letters a-z", fields: map[string]string{"author": "王, 伟", "editor": "Pike, Rob", "year": "2020"}, want: "pike2020"},
+		{name: "Title", fields: map[string]string{"title": "Σ 42 Simplicity", "year": "2020"}, want: "anonymous2020"},
+		{name: "Without year", fields: map[string]string{"author": "Welter, Rudolf"}, err: true},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := getBibkey(zet.BibEntry{Type: "book", Fields: tc.fields}, map[string]bool{})
+			if (err != nil) != tc.err {
+				t.Errorf("got error %v, want an error: %v", err, tc.err)
+			}
+			if got != tc.want {
+				t.Errorf("got bibkey %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestImportUnbalancedReferences(t *testing.T) {
+	pathTestRepo := t.TempDir()
+	bib := "@book{welter2011,\n\ttitle = {Komplexität},\n\tyear = {2011}}"
+	if err := os.WriteFile(path.Join(pathTestRepo, "references.bib"), []byte(bib), 0644); err != nil {
+		t.Fatal(err)
+	}
+	ris := path.Join(pathTestRepo, "unbalanced.ris")
+	if err := os.WriteFile(ris, []byte("TY  - BOOK\nAU  - Pike, Rob\nTI  - The } in C\nPY  - 1989\nER  - \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	p := parse.New()
+	repo := fsRepo.New(pathTestRepo, p)
+	importer := New(p, repo, repo)
+
+	if n, err := importer.ImportReferences(ris); err == nil {
+		t.Errorf("imported %v references with unbalanced braces, want an error", n)
+	}
+	got, err := os.ReadFile(path.Join(pathTestRepo, "references.bib"))
+	if err != nil || string(got) != bib {
+		t.Errorf("references.bib changed to %q (%v)", got, err)
+	}
+}
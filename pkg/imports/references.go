@@ -0,0 +1,164 @@
+package imports
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// transliterations replaces letters, which are not allowed in a bibkey, by their closest ASCII form.
+var transliterations = strings.NewReplacer(
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+	"á", "a", "à", "a", "â", "a", "å", "a", "ã", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ø", "o", "õ", "o",
+	"ú", "u", "ù", "u", "û", "u",
+	"ç", "c", "ñ", "n",
+)
+
+// ImportReferences reads a RIS (.ris) or EndNote XML (.xml) export of literature references from the parameter path.
+// Every reference gets a bibkey in the form AUTHORYEAR, e.g. "welter2011".
+// If this bibkey already exists in your references.bib, a letter is added, e.g. "welter2011a".
+// The references are appended to your references.bib, the existing entries stay untouched.
+//
+// In case of success ImportReferences returns the number of references added and a nil error.
+// In case of an error ImportReferences returns 0 (no references are added) and the error.
+func (i Importer) ImportReferences(path string) (int, error) {
+	contents, err := i.reader.GetContents(path)
+	if err != nil {
+		return 0, err
+	}
+	if len(contents) != 1 {
+		return 0, fmt.Errorf("imports: %q should be a single RIS or EndNote XML file", path)
+	}
+
+	format := strings.TrimPrefix(filepath.Ext(path), ".")
+	entries, err2 := i.parser.ReferenceList(contents[0], format)
+	if err2 != nil {
+		return 0, err2
+	}
+	if len(entries) == 0 {
+		return 0, fmt.Errorf("imports: no references found in %q", path)
+	}
+
+	bibkeys, err3 := i.repo.GetBibkeys()
+	if err3 != nil {
+		return 0, err3
+	}
+	taken := make(map[string]bool)
+	for _, b := range bibkeys {
+		taken[b] = true
+	}
+
+	for n := range entries {
+		key, err := getBibkey(entries[n], taken)
+		if err != nil {
+			return 0, err
+		}
+		entries[n].Key = key
+		taken[key] = true
+	}
+	for _, e := range entries {
+		if field := unbalancedField(e); field != "" {
+			return 0, fmt.Errorf("imports: the field %v of reference %v has unbalanced braces '{' and '}', which would break your references.bib", field, e.Key)
+		}
+	}
+
+	err4 := i.repo.AddReferences(i.parser.BibTeX(entries))
+	if err4 != nil {
+		return 0, err4
+	}
+	return len(entries), nil
+}
+
+// getBibkey returns a bibkey in the form AUTHORYEAR[LETTER] that is not yet taken, e.g. "welter2011" or "welter2011b".
+// AUTHOR is the last name of the first author in lower case letters a-z. If there is no author or the name
+// has less than two of these letters, the editor, the first word of the title or else "anonymous" is used.
+// A reference without a year of four digits gets no bibkey, since it could not be cited in a filename,
+// so an error is returned, as well as if the bibkey and all its letters are taken.
+func getBibkey(e zet.BibEntry, taken map[string]bool) (string, error) {
+	year := yearRegex.FindString(e.Fields["year"])
+	if year == "" {
+		return "", fmt.Errorf("imports: reference %q has no year, which is needed for its bibkey", e.Fields["title"])
+	}
+
+	var author string
+	for _, name := range []string{familyName(e.Fields["author"]), familyName(e.Fields["editor"]), firstWord(e.Fields["title"]), "anonymous"} {
+		if author = letters(name); len(author) >= 2 {
+			break
+		}
+	}
+
+	key := author + year
+	bibkey := key
+	for letter := 'a'; taken[bibkey]; letter++ {
+		if letter > 'z' {
+			return "", fmt.Errorf("imports: the bibkeys %v to %vz are all taken", key, key)
+		}
+		bibkey = key + string(letter)
+	}
+	return bibkey, nil
+}
+
+var yearRegex = regexp.MustCompile(`\d{4}`)
+
+// letters returns the name in lower case with only the letters a-z, other letters are transliterated if possible.
+func letters(name string) string {
+	var b strings.Builder
+	for _, r := range transliterations.Replace(strings.ToLower(name)) {
+		if r >= 'a' && r <= 'z' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func firstWord(s string) string {
+	if f := strings.Fields(s); len(f) > 0 {
+		return f[0]
+	}
+	return ""
+}
+
+// unbalancedField returns the name of the first field of the entry, whose braces are not balanced, or "".
+func unbalancedField(e zet.BibEntry) string {
+	var names []string
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		depth := 0
+		for _, r := range e.Fields[name] {
+			if r == '{' {
+				depth++
+			} else if r == '}' {
+				depth--
+			}
+			if depth < 0 {
+				break
+			}
+		}
+		if depth != 0 {
+			return name
+		}
+	}
+	return ""
+}
+
+// familyName returns the last name of the first person in a BibTeX name list like "Welter, Thomas and Rob Pike".
+func familyName(names string) string {
+	first := strings.TrimSpace(strings.Split(names, " and ")[0])
+	if i := strings.Index(first, ","); i != -1 {
+		return strings.TrimSpace(first[:i])
+	}
+	parts := strings.Fields(first)
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
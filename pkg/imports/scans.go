@@ -0,0 +1,148 @@
+package imports
+
+import (
+	"encoding/csv"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// scanExtensions are the file types of scans, e.g. of a handwritten DIN A6 zettel.
+var scanExtensions = []string{".png", ".pdf"}
+
+// manifestFile optionally holds the metadata of all scans in a folder, one line per scan.
+const manifestFile = "manifest.csv"
+
+// ImportScans reads all scans (.png or .pdf) from the folder under the parameter path.
+// The metadata of a scan is taken from a sidecar textfile with the same name (e.g. "scan01.txt" for "scan01.png")
+// or from a line in the file manifest.csv within the same folder.
+//
+// A sidecar textfile has the same header as a text zettel. If the sidecar textfile contains text after the
+// header (e.g. a transcription of a handwritten zettel), it is saved next to the scan under the same filename
+// and therefore under the same id.
+//
+// A line in manifest.csv has the form 'file;keywords;date;context', e.g.
+//
+//	scan01.png;Complexity, Entropy;12.1.2020;welter2011 243
+//
+// In case of success ImportScans returns the number of zettel created and a nil error.
+// In case of an error ImportScans returns 0 (no files are created) and the error.
+func (i Importer) ImportScans(path string) (int, error) {
+	files, err := i.reader.GetFiles(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var manifest map[string]string
+	if m, ok := files[manifestFile]; ok {
+		manifest, err = parseManifest(string(m))
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	zettel, _, err2 := i.repo.GetZettel()
+	if err2 != nil {
+		return 0, err2
+	}
+
+	var scans []string
+	for name := range files {
+		if isScan(name) {
+			scans = append(scans, name)
+		}
+	}
+	// Sort the scans to make sure that the ids are always generated in the same order.
+	sort.Strings(scans)
+
+	zettelFiles := make(map[string][]byte)
+	for _, scan := range scans {
+		ext := filepath.Ext(scan)
+		sidecar, hasSidecar := files[strings.TrimSuffix(scan, ext)+".txt"]
+
+		var content string
+		switch {
+		case hasSidecar:
+			content = string(sidecar)
+		case manifest[scan] != "":
+			content = manifest[scan]
+		default:
+			return 0, fmt.Errorf("imports: no sidecar textfile and no entry in %v for scan %q", manifestFile, scan)
+		}
+
+		filename, err3 := i.parser.Content(content, zettel)
+		if err3 != nil {
+			return 0, fmt.Errorf("imports: scan %q: %v", scan, err3)
+		}
+		scanFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + strings.ToLower(ext)
+		zettelFiles[scanFilename] = files[scan]
+		if hasSidecar && hasTranscription(content) {
+			zettelFiles[filename] = sidecar
+		}
+
+		// Make sure that a following import is not using the same id as this zettel.
+		z, err4 := i.parser.Filename(scanFilename)
+		if err4 != nil {
+			return 0, err4
+		}
+		zettel = append(zettel, z)
+	}
+
+	n, err5 := i.repo.Save(zettelFiles)
+	if err5 != nil {
+		return n, err5
+	}
+
+	return len(scans), nil
+}
+
+func isScan(filename string) bool {
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, e := range scanExtensions {
+		if ext == e {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTranscription checks if the content of a sidecar textfile has some text after its header.
+// The header is separated from the text by a blank line.
+func hasTranscription(content string) bool {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	i := strings.Index(content, "\n\n")
+	if i == -1 {
+		return false
+	}
+	return strings.TrimSpace(content[i:]) != ""
+}
+
+// parseManifest returns for every scan in the manifest the header of its zettel (map[scan filename]header).
+func parseManifest(content string) (map[string]string, error) {
+	r := csv.NewReader(strings.NewReader(content))
+	r.Comma = ';'
+	r.Comment = '#'
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("imports: could not read %v: %v", manifestFile, err)
+	}
+
+	headers := make(map[string]string)
+	for n, record := range records {
+		if n == 0 && strings.EqualFold(strings.TrimSpace(record[0]), "file") {
+			// Skip the optional column names.
+			continue
+		}
+		if len(record) < 3 || len(record) > 4 {
+			return nil, fmt.Errorf("imports: could not parse entry %d of %v, should be 'file;keywords;date;context'", n+1, manifestFile)
+		}
+		var header []string
+		for _, field := range record[1:] {
+			header = append(header, strings.TrimSpace(field))
+		}
+		headers[strings.TrimSpace(record[0])] = strings.Join(header, "\n")
+	}
+	return headers, nil
+}
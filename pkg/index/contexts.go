@@ -0,0 +1,44 @@
+package index
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+)
+
+const contextsFolder = "CONTEXTS"
+
+// CreateContexts creates the folder 'CONTEXTS', which contains for every context declared in your contexts.txt
+// a folder 'CONTEXTS/<type>/<name>/' with links to all zettel having this context by its name or by one
+// of its aliases, e.g. 'CONTEXTS/Person/Anna Müller/'.
+//
+// Contexts of zettel that are not declared in your contexts.txt are left out; 'zet validate' lists them.
+func (i Indexer) CreateContexts() error {
+	zettel, _, err := i.Repo.GetZettel()
+	if err != nil {
+		return fmt.Errorf("error creating contexts: %w", err)
+	}
+	contexts, _, err := i.Repo.GetContexts()
+	if err != nil {
+		return fmt.Errorf("error creating contexts: %w", err)
+	}
+
+	_, err = i.Persister.PersistView(contextsFolder, getContextLinks(zettel, contexts), nil, false)
+	return err
+}
+
+// getContextLinks returns the links for the folder 'CONTEXTS' in the form of links[linkName]targetId, e.g.
+//
+//	Person/Anna Müller/170224a - Complexity - Anna.txt
+func getContextLinks(zettel []zet.Zettel, contexts []zet.ContextEntry) map[string]string {
+	links := make(map[string]string)
+	for _, z := range zettel {
+		for _, context := range z.Context {
+			for _, c := range contexts {
+				if c.Matches(context) {
+					links[c.Type+"/"+c.Name+"/"+z.Name] = z.Id
+				}
+			}
+		}
+	}
+	return links
+}
@@ -0,0 +1,60 @@
+package index
+
+import (
+	"fmt"
+)
+
+// AddToIndex adds the ids to the topic in your index.txt. If the topic doesn't exist, it gets created.
+// All ids must belong to an existing zettel.
+func (i Indexer) AddToIndex(topic string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("index: no ids provided for topic %q", topic)
+	}
+	zettel, _, err := i.Repo.GetZettel()
+	if err != nil {
+		return fmt.Errorf("error editing index: %w", err)
+	}
+	existing := make(map[string]bool)
+	for _, z := range zettel {
+		existing[z.Id] = true
+	}
+	for _, id := range ids {
+		if !existing[id] {
+			return fmt.Errorf("index: zettel with id %v not found", id)
+		}
+	}
+
+	return i.edit(func(content string) (string, error) {
+		return i.Editor.AddToIndex(content, topic, ids...)
+	})
+}
+
+// RemoveFromIndex removes the ids from the topic in your index.txt.
+// A topic without ids and references to other topics gets removed.
+func (i Indexer) RemoveFromIndex(topic string, ids []string) error {
+	if len(ids) == 0 {
+		return fmt.Errorf("index: no ids provided for topic %q", topic)
+	}
+	return i.edit(func(content string) (string, error) {
+		return i.Editor.RemoveFromIndex(content, topic, ids...)
+	})
+}
+
+// RenameTopic renames the topic in your index.txt including all references to it.
+func (i Indexer) RenameTopic(oldTopic, newTopic string) error {
+	return i.edit(func(content string) (string, error) {
+		return i.Editor.RenameTopic(content, oldTopic, newTopic)
+	})
+}
+
+func (i Indexer) edit(edit func(content string) (string, error)) error {
+	content, err := i.Persister.GetIndexContent()
+	if err != nil {
+		return fmt.Errorf("error editing index: %w", err)
+	}
+	edited, err := edit(content)
+	if err != nil {
+		return err
+	}
+	return i.Persister.SaveIndex(edited)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/crelder/zet"
 	"path"
+	"strings"
 )
 
 // Indexer contains the application entry point for all operations regarding views upon your zettelkasten.
@@ -11,63 +12,87 @@ import (
 type Indexer struct {
 	Persister Persister
 	Repo      zet.Repo
+	Editor    Editor
 }
 
+// Persister persists views upon your zettelkasten and your edits of the index.
+//
+// PersistView brings the folder up to date with a view consisting of links and additional textfiles and
+// returns the changes it made. With dryRun, it only returns the changes.
+//
+// GetIndexContent returns the unparsed content of your index.txt, SaveIndex replaces it.
 type Persister interface {
-	PersistIndex(links map[string]string) error // links[linkName]targetID
+	PersistView(folder string, links map[string]string, files map[string]string, dryRun bool) ([]string, error) // links[linkName]targetID
+	GetIndexContent() (string, error)
+	SaveIndex(content string) error
 }
 
-func New(p Persister, r zet.Repo) Indexer {
+// Editor edits the content of an index and returns the new content.
+type Editor interface {
+	AddToIndex(content, topic string, ids ...string) (string, error)
+	RemoveFromIndex(content, topic string, ids ...string) (string, error)
+	RenameTopic(content, oldTopic, newTopic string) (string, error)
+}
+
+const indexFolder = "INDEX"
+
+func New(p Persister, r zet.Repo, e Editor) Indexer {
 	return Indexer{
 		Persister: p,
 		Repo:      r,
+		Editor:    e,
 	}
 }
 
-func (i Indexer) Create() error {
+// Create brings the folder 'INDEX' up to date with your index.txt and your zettel.
+// Only the links that changed since the last run are added, renamed or removed.
+// It returns the changes, with dryRun they are only listed, not made.
+func (i Indexer) Create(dryRun bool) ([]string, error) {
 	zettel, _, err := i.Repo.GetZettel()
 	if err != nil {
-		return fmt.Errorf("error creating views: %w", err)
+		return nil, fmt.Errorf("error creating views: %w", err)
 	}
 	index, _, err := i.Repo.GetIndex()
 	if err != nil {
-		return fmt.Errorf("error creating index: %w", err)
+		return nil, fmt.Errorf("error creating index: %w", err)
 	}
 
 	// Create a method, which returns all paths like "Komplexität/180215a - Komplexität, ..../180215a - Komplexität, ..."
 	folgezettelMap, err := getFolgezettelMap(zettel, index)
 	if err != nil {
-		return err
-	}
-	// PersistIndex all these paths via a call v.ViewPersister.PersistIndex(map[paths][]ids). It creates already everything in "zettelkasten/INDEX/"
-	// Concrete Implementierung heißt FsIndexPersister.
-	err = i.Persister.PersistIndex(folgezettelMap)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return i.Persister.PersistView(indexFolder, folgezettelMap, getSeeAlsoFiles(index), dryRun)
+}
+
+// getSeeAlsoFiles returns for every topic referring to other topics a file 'see also.txt' in the folder
+// of the topic, which lists the other topics line by line, e.g. 'Thermodynamics/see also.txt'.
+func getSeeAlsoFiles(index zet.Index) map[string]string {
+	files := make(map[string]string)
+	for _, e := range index {
+		if len(e.SeeAlso) == 0 {
+			continue
+		}
+		files[e.Topic+"/see also.txt"] = strings.Join(e.SeeAlso, "\n") + "\n"
+	}
+	return files
 }
 
 // getFolgezettelMap contains the business logic for converting the
 // tree structure of a zettelkasten into a flat structure in a file directory.
 func getFolgezettelMap(zettel []zet.Zettel, index zet.Index) (map[string]string, error) {
 	var result = make(map[string]string)
-	for topic := range index {
-		for _, id := range index[topic] {
+	for _, e := range index {
+		for _, id := range e.Ids {
 			var err error
-			result, err = mergeMaps(result, getFolgezettel(id, topic, zettel))
+			result, err = mergeMaps(result, getFolgezettel(id, e.Topic, zettel))
 			if err != nil {
 				return nil, err
 			}
 		}
 	}
-	result2 := make(map[string]string)
-	for p, id := range result {
-		const indexFolder = "INDEX/"
-		result2[indexFolder+p] = id
-	}
-	return result2, nil
+	return result, nil
 }
 
 // getFolgezettel returns links that represent the
@@ -3,7 +3,10 @@ package index
 import (
 	"github.com/crelder/zet/pkg/parse"
 	"github.com/crelder/zet/pkg/transport/fs"
+	"github.com/google/go-cmp/cmp"
 	"os"
+	"sort"
+	"strings"
 	"testing"
 )
 
@@ -16,14 +19,14 @@ func TestCreateIndexViews(t *testing.T) {
 	var pathTestRepo = wd + "/testdata/zettelkasten"
 	parser := parse.New()
 	repo := fs.New(pathTestRepo, parser)
-	indexer := New(repo, repo)
+	indexer := New(repo, repo, parser)
 
 	// Remove this directory, which might got created in a previous test
 	viewPath := pathTestRepo + "/INDEX"
 	clearPath(viewPath)
 
 	// Act
-	err = indexer.Create()
+	_, err = indexer.Create(false)
 	if err != nil {
 		t.Errorf("Could not generate views: %v", err)
 	}
@@ -53,6 +56,96 @@ func TestCreateIndexViews(t *testing.T) {
 			t.Errorf("link was not created: %+v, ", tc)
 		}
 	}
+
+	// A topic referring to other topics lists them in a file.
+	seeAlso, err := os.ReadFile(pathTestRepo + "/INDEX/Thermodynamik/see also.txt")
+	if err != nil {
+		t.Errorf("could not read see also: %v", err)
+	}
+	if string(seeAlso) != "Komplexität\n" {
+		t.Errorf("Got see also %q, wanted %q", seeAlso, "Komplexität\n")
+	}
+}
+
+func TestUpdateIndexViews(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = wd + "/testdata/zettelkasten"
+	parser := parse.New()
+	repo := fs.New(pathTestRepo, parser)
+	indexer := New(repo, repo, parser)
+	clearPath(pathTestRepo + "/INDEX")
+
+	if _, err := indexer.Create(false); err != nil {
+		t.Fatalf("Could not generate views: %v", err)
+	}
+
+	// A link that moved in the tree, a link to an outdated file and a file that doesn't belong to the view.
+	moved := pathTestRepo + "/INDEX/Komplexität/220122a/000 220122a - Some keyword.txt"
+	if err := os.Rename(moved, pathTestRepo+"/INDEX/Komplexität/old.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathTestRepo+"/INDEX/Thermodynamik/see also.txt", []byte("Entropie\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(pathTestRepo+"/INDEX/Obsolete", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathTestRepo+"/INDEX/Obsolete/note.txt", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	// Files of a type a view never writes are kept.
+	if err := os.WriteFile(pathTestRepo+"/INDEX/Thermodynamik/notes.bib", nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"remove INDEX/Obsolete/note.txt",
+		"rename INDEX/Komplexität/old.txt -> INDEX/Komplexität/220122a/000 220122a - Some keyword.txt",
+		"update INDEX/Thermodynamik/see also.txt",
+	}
+
+	// Act & Assert
+	// A dry run only lists the changes.
+	got, err := indexer.Create(true)
+	if err != nil {
+		t.Errorf("Could not list changes: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	if _, err := os.Stat(pathTestRepo + "/INDEX/Obsolete/note.txt"); err != nil {
+		t.Errorf("dry run changed the folder 'INDEX': %v", err)
+	}
+
+	got, err = indexer.Create(false)
+	if err != nil {
+		t.Errorf("Could not update views: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	if _, err := os.Stat(moved); err != nil {
+		t.Errorf("link was not renamed: %v", err)
+	}
+	if _, err := os.Stat(pathTestRepo + "/INDEX/Obsolete"); !os.IsNotExist(err) {
+		t.Errorf("empty folder was not removed: %v", err)
+	}
+	if _, err := os.Stat(pathTestRepo + "/INDEX/Thermodynamik/notes.bib"); err != nil {
+		t.Errorf("file of another type was removed: %v", err)
+	}
+
+	// Running it again changes nothing.
+	got, err = indexer.Create(false)
+	if err != nil {
+		t.Errorf("Could not update views: %v", err)
+	}
+	if len(got) != 0 {
+		t.Errorf("Got changes %v, wanted none", got)
+	}
 }
 
 func clearPath(path string) {
@@ -61,3 +154,239 @@ func clearPath(path string) {
 		println("Error occurred: %v", err)
 	}
 }
+
+func TestCreateReferences(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = wd + "/testdata/zettelkasten"
+	parser := parse.New()
+	repo := fs.New(pathTestRepo, parser)
+	indexer := New(repo, repo, parser)
+
+	// A summary of a reference no longer cited by any zettel.
+	if err := os.MkdirAll(pathTestRepo+"/REFERENCES", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(pathTestRepo+"/REFERENCES/knuth1997.md", []byte("# Outdated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Act
+	// Creating the references twice should work, since the folder is replaced.
+	for i := 0; i < 2; i++ {
+		err = indexer.CreateReferences()
+		if err != nil {
+			t.Errorf("Could not generate references: %v", err)
+		}
+	}
+
+	// Assert
+	testcases := []string{
+		// The zettel citing a reference are sorted by their location, page 5 comes before page 87.
+		"REFERENCES/clausen2021/000 220115p - Refactoring, Programmieren - Marco Fitz, clausen2021 5.pdf",
+		"REFERENCES/clausen2021/001 190119d - Testing - clausen2021 87 - 190119e.txt",
+		"REFERENCES/clausen2021.md",
+
+		// A reference which is not in the references.bib also gets a folder.
+		"REFERENCES/kernighan2016/000 210328obj - Objektorientiert, Programmierung - kernighan2016 155.pdf",
+	}
+
+	for _, tc := range testcases {
+		if _, err := os.Stat(pathTestRepo + "/" + tc); err != nil {
+			t.Errorf("link was not created: %+v, ", tc)
+		}
+	}
+
+	if _, err := os.Stat(pathTestRepo + "/REFERENCES/knuth1997.md"); !os.IsNotExist(err) {
+		t.Errorf("outdated summary was not removed: %v", err)
+	}
+
+	summary, err := os.ReadFile(pathTestRepo + "/REFERENCES/clausen2021.md")
+	if err != nil {
+		t.Errorf("could not read summary: %v", err)
+	}
+	want := `# Software Testing
+
+Bibkey: clausen2021  
+Author: Clausen, Lennart  
+Year: 2021  
+
+## Zettel
+
+- 5: 220115p - Refactoring, Programmieren
+- 87: 190119d - Testing
+
+## Keywords
+
+- Programmieren (1)
+- Refactoring (1)
+- Testing (1)
+`
+	if string(summary) != want {
+		t.Errorf("Got summary %q, wanted %q", summary, want)
+	}
+}
+
+func TestCreateContexts(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	var pathTestRepo = wd + "/testdata/zettelkasten"
+	parser := parse.New()
+	repo := fs.New(pathTestRepo, parser)
+	indexer := New(repo, repo, parser)
+
+	// Act
+	err = indexer.CreateContexts()
+	if err != nil {
+		t.Errorf("Could not generate contexts: %v", err)
+	}
+
+	// Assert
+	testcases := []string{
+		"CONTEXTS/Person/Marco Fitz/220115p - Refactoring, Programmieren - Marco Fitz, clausen2021 5.pdf",
+		"CONTEXTS/Person/Marco Fitz/220116s - Spezifikation - Marco Fitz - 180522a.pdf",
+	}
+
+	for _, tc := range testcases {
+		if _, err := os.Stat(pathTestRepo + "/" + tc); err != nil {
+			t.Errorf("link was not created: %+v, ", tc)
+		}
+	}
+}
+
+func TestNaturalLess(t *testing.T) {
+	locations := []string{"120-122", "14f", "9", "", "ch. 3", "14"}
+	sort.Slice(locations, func(i, j int) bool {
+		return naturalLess(locations[i], locations[j])
+	})
+
+	want := []string{"", "9", "14", "14f", "120-122", "ch. 3"}
+	if diff := cmp.Diff(want, locations); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestEditIndex(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.Mkdir(dir+"/zettel", 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range []string{"190119e - Komplexität.txt", "220122a - Some keyword.txt"} {
+		if err := os.WriteFile(dir+"/zettel/"+f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	index := "# Starting points\nKomplexität: 190119e\nThermodynamik: see Komplexität\n"
+	if err := os.WriteFile(dir+"/index.txt", []byte(index), 0644); err != nil {
+		t.Fatal(err)
+	}
+	parser := parse.New()
+	repo := fs.New(dir, parser)
+	indexer := New(repo, repo, parser)
+
+	// Act
+	if err := indexer.AddToIndex("Komplexität", []string{"220122a"}); err != nil {
+		t.Errorf("Could not add to index: %v", err)
+	}
+	if err := indexer.RenameTopic("Komplexität", "Systemtheorie"); err != nil {
+		t.Errorf("Could not rename topic: %v", err)
+	}
+	if err := indexer.RemoveFromIndex("Systemtheorie", []string{"190119e"}); err != nil {
+		t.Errorf("Could not remove from index: %v", err)
+	}
+	// An id of a zettel, which doesn't exist, must not be added.
+	err := indexer.AddToIndex("Komplexität", []string{"170224a"})
+	if err == nil || err.Error() != "index: zettel with id 170224a not found" {
+		t.Errorf("Got error %v, wanted an error for the unknown id", err)
+	}
+
+	// Assert
+	got, err := os.ReadFile(dir + "/index.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# Starting points\nSystemtheorie: 220122a\nThermodynamik: see Systemtheorie\n"
+	if diff := cmp.Diff(want, string(got)); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestLinkStrategies(t *testing.T) {
+	tcs := []struct {
+		strategy string
+		link     string
+		check    func(link, zettel string) bool
+	}{
+		{"hardlink", "000 190119e - Komplexität.txt", func(link, zettel string) bool {
+			l, _ := os.Lstat(link)
+			z, _ := os.Stat(zettel)
+			return os.SameFile(l, z)
+		}},
+		{"symlink", "000 190119e - Komplexität.txt", func(link, zettel string) bool {
+			dest, err := os.Readlink(link)
+			return err == nil && dest == "../../../zettel/190119e - Komplexität.txt"
+		}},
+		{"copy", "000 190119e - Komplexität.txt", func(link, zettel string) bool {
+			l, _ := os.Lstat(link)
+			z, _ := os.Stat(zettel)
+			return l.Mode().IsRegular() && !os.SameFile(l, z)
+		}},
+		{"url", "000 190119e - Komplexität.txt.url", func(link, zettel string) bool {
+			f, _ := os.ReadFile(link)
+			return strings.HasPrefix(string(f), "[InternetShortcut]\r\nURL=file:///")
+		}},
+		{"desktop", "000 190119e - Komplexität.txt.desktop", func(link, zettel string) bool {
+			f, _ := os.ReadFile(link)
+			return strings.HasPrefix(string(f), "[Desktop Entry]\nType=Link\nName=190119e - Komplexität.txt\nURL=file:///")
+		}},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.strategy, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			if err := os.Mkdir(dir+"/zettel", 0755); err != nil {
+				t.Fatal(err)
+			}
+			zettel := dir + "/zettel/190119e - Komplexität.txt"
+			if err := os.WriteFile(zettel, []byte("Complexity"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(dir+"/index.txt", []byte("Komplexität: 190119e\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(dir+"/config.txt", []byte("[views]\nlink strategy = "+tc.strategy+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+			parser := parse.New()
+			repo := fs.New(dir, parser)
+			indexer := New(repo, repo, parser)
+
+			// Act
+			if _, err := indexer.Create(false); err != nil {
+				t.Fatalf("Could not generate views: %v", err)
+			}
+
+			// Assert
+			link := dir + "/INDEX/Komplexität/190119e/" + tc.link
+			if !tc.check(link, zettel) {
+				t.Errorf("link %v was not created as %v", tc.link, tc.strategy)
+			}
+			// Running it again changes nothing.
+			changes, err := indexer.Create(false)
+			if err != nil {
+				t.Errorf("Could not update views: %v", err)
+			}
+			if len(changes) != 0 {
+				t.Errorf("Got changes %v, wanted none", changes)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package index
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const referencesFolder = "REFERENCES"
+
+// citation is a zettel citing a literature reference at a location, e.g. a page.
+type citation struct {
+	zettel   zet.Zettel
+	location zet.Location
+}
+
+// CreateReferences creates the folder 'REFERENCES', which contains for every literature reference that is cited
+// by at least one zettel:
+//   - a folder 'REFERENCES/<bibkey>/' with links to all zettel citing this reference,
+//     sorted by their location within the reference (e.g. page numbers) and
+//   - a summary 'REFERENCES/<bibkey>.md' with the title of the reference and the keywords of the zettel.
+//
+// Summaries of references that are no longer cited are removed. They are not written to a folder 'references',
+// since it would be the same folder as 'REFERENCES' on case-insensitive filesystems.
+//
+// When writing about a book, this gives you all your thoughts on it in the order of the book.
+func (i Indexer) CreateReferences() error {
+	zettel, _, err := i.Repo.GetZettel()
+	if err != nil {
+		return fmt.Errorf("error creating references: %w", err)
+	}
+	references, _, err := i.Repo.GetReferences()
+	if err != nil {
+		return fmt.Errorf("error creating references: %w", err)
+	}
+
+	citations := getCitations(zettel)
+	links := getReferenceLinks(citations)
+	summaries := getSummaries(citations, references)
+
+	_, err = i.Persister.PersistView(referencesFolder, links, summaries, false)
+	return err
+}
+
+// getCitations returns for every bibkey all zettel citing it, sorted by their location within the reference.
+// Pages come after chapters and sections, since they can not be compared with each other.
+func getCitations(zettel []zet.Zettel) map[string][]citation {
+	citations := make(map[string][]citation)
+	for _, z := range zettel {
+		for _, r := range z.References {
+			citations[r.Bibkey] = append(citations[r.Bibkey], citation{zettel: z, location: r.Location})
+		}
+	}
+
+	for _, c := range citations {
+		sort.SliceStable(c, func(i, j int) bool {
+			if c[i].location.Raw != c[j].location.Raw {
+				return locationLess(c[i].location, c[j].location)
+			}
+			return c[i].zettel.Id < c[j].zettel.Id
+		})
+	}
+	return citations
+}
+
+// getReferenceLinks returns the links for the folder 'REFERENCES' in the form of links[linkName]targetId, e.g.
+//
+//	welter2011/000 170224a - Complexity - welter2011 12.txt
+func getReferenceLinks(citations map[string][]citation) map[string]string {
+	links := make(map[string]string)
+	for bibkey, cs := range citations {
+		for n, c := range cs {
+			links[bibkey+"/"+fmt.Sprintf("%03d", n)+" "+c.zettel.Name] = c.zettel.Id
+		}
+	}
+	return links
+}
+
+// getSummaries returns for every cited reference a markdown summary in the form of summaries[filename]content.
+func getSummaries(citations map[string][]citation, references []zet.BibEntry) map[string]string {
+	entries := make(map[string]zet.BibEntry)
+	for _, r := range references {
+		entries[r.Key] = r
+	}
+
+	summaries := make(map[string]string)
+	for bibkey, cs := range citations {
+		summaries[bibkey+".md"] = getSummary(bibkey, cs, entries[bibkey])
+	}
+	return summaries
+}
+
+func getSummary(bibkey string, citations []citation, entry zet.BibEntry) string {
+	title := entry.Fields["title"]
+	if title == "" {
+		title = bibkey
+	}
+
+	var lines []string
+	lines = append(lines, "# "+title, "")
+	lines = append(lines, "Bibkey: "+bibkey+"  ")
+	if author := entry.Fields["author"]; author != "" {
+		lines = append(lines, "Author: "+author+"  ")
+	}
+	if year := entry.Fields["year"]; year != "" {
+		lines = append(lines, "Year: "+year+"  ")
+	}
+
+	lines = append(lines, "", "## Zettel", "")
+	keywords := make(map[string]int)
+	for _, c := range citations {
+		var location string
+		if c.location.Raw != "" {
+			location = c.location.Raw + ": "
+		}
+		lines = append(lines, "- "+location+c.zettel.Id+" - "+strings.Join(c.zettel.Keywords, ", "))
+		for _, k := range c.zettel.Keywords {
+			keywords[k]++
+		}
+	}
+
+	var kws []string
+	for k := range keywords {
+		kws = append(kws, k)
+	}
+	sort.Slice(kws, func(i, j int) bool {
+		if keywords[kws[i]] != keywords[kws[j]] {
+			return keywords[kws[i]] > keywords[kws[j]]
+		}
+		return kws[i] < kws[j]
+	})
+
+	lines = append(lines, "", "## Keywords", "")
+	for _, k := range kws {
+		lines = append(lines, "- "+k+" ("+strconv.Itoa(keywords[k])+")")
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// locationOrder defines the order of the kinds of locations within a reference.
+// Pages and page ranges are sorted together by their (first) page.
+var locationOrder = map[zet.LocationKind]int{
+	zet.NoLocation:        0,
+	zet.ChapterLocation:   1,
+	zet.SectionLocation:   2,
+	zet.PageLocation:      3,
+	zet.PageRangeLocation: 3,
+	zet.TimestampLocation: 4,
+	zet.UnknownLocation:   5,
+}
+
+// locationLess compares two locations within a reference, e.g. page 9 comes before the pages 14-16.
+// Locations that could not be parsed are sorted last in a natural order.
+func locationLess(a, b zet.Location) bool {
+	if locationOrder[a.Kind] != locationOrder[b.Kind] {
+		return locationOrder[a.Kind] < locationOrder[b.Kind]
+	}
+	if a.Kind == zet.UnknownLocation {
+		return naturalLess(a.Raw, b.Raw)
+	}
+	for i := 0; i < len(a.Values) && i < len(b.Values); i++ {
+		if a.Values[i] != b.Values[i] {
+			return a.Values[i] < b.Values[i]
+		}
+	}
+	return len(a.Values) < len(b.Values)
+}
+
+// naturalLess compares two strings in a natural order, where numbers are compared by their value,
+// e.g. "9" < "14f" < "120-122".
+func naturalLess(a, b string) bool {
+	for a != "" && b != "" {
+		ca, restA := nextChunk(a)
+		cb, restB := nextChunk(b)
+		if ca != cb {
+			na, errA := strconv.Atoi(ca)
+			nb, errB := strconv.Atoi(cb)
+			switch {
+			case errA == nil && errB == nil:
+				if na != nb {
+					return na < nb
+				}
+			case errA == nil:
+				return true
+			case errB == nil:
+				return false
+			}
+			return ca < cb
+		}
+		a, b = restA, restB
+	}
+	return len(a) < len(b)
+}
+
+// nextChunk returns the leading number or the leading non-numerical characters of s and the rest of s.
+func nextChunk(s string) (string, string) {
+	isDigit := unicode.IsDigit(rune(s[0]))
+	i := 1
+	for i < len(s) && unicode.IsDigit(rune(s[i])) == isDigit {
+		i++
+	}
+	return s[:i], s[i:]
+}
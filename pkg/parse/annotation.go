@@ -0,0 +1,192 @@
+package parse
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/crelder/zet"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Annotations parses a CSV or JSON export of annotations, e.g. from Zotero or Readwise.
+// The parameter format is either "csv" or "json".
+//
+// The columns (CSV) or fields (JSON) are matched by name, independent of upper and lower case, spaces and
+// underscores, so that e.g. "Citation Key", "citationKey" and "citation_key" are all recognized.
+// A JSON export is expected to be a list of annotation objects.
+func Annotations(content, format string) ([]zet.Annotation, error) {
+	var records []map[string]string
+	var err error
+	switch strings.ToLower(format) {
+	case "csv":
+		records, err = csvRecords(content)
+	case "json":
+		records, err = jsonRecords(content)
+	default:
+		return nil, fmt.Errorf("parse annotations: unknown format %q, should be csv or json", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var annotations []zet.Annotation
+	for i, record := range records {
+		a, err := toAnnotation(record)
+		if err != nil {
+			return nil, fmt.Errorf("parse annotations: annotation %d: %v", i+1, err)
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, nil
+}
+
+// annotationFields maps the fields of an annotation to all known (normalized) column names of the exports.
+var annotationFields = map[string][]string{
+	"text":     {"highlight", "text", "annotationtext"},
+	"comment":  {"note", "comment", "annotationcomment"},
+	"bibkey":   {"citationkey", "citekey", "bibkey"},
+	"location": {"page", "pagelabel", "annotationpage", "annotationpagelabel", "location"},
+	"tags":     {"tags", "manualtags", "annotationtags"},
+	"date":     {"highlightedat", "dateadded", "date", "created", "createdat", "datemodified"},
+}
+
+func toAnnotation(record map[string]string) (zet.Annotation, error) {
+	field := func(name string) string {
+		for _, column := range annotationFields[name] {
+			if v := strings.TrimSpace(record[column]); v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	a := zet.Annotation{
+		Text:     field("text"),
+		Comment:  field("comment"),
+		Bibkey:   field("bibkey"),
+		Location: field("location"),
+		Tags:     parseTags(field("tags")),
+	}
+	if a.Text == "" && a.Comment == "" {
+		return zet.Annotation{}, errors.New("neither text nor comment provided")
+	}
+	if a.Bibkey == "" {
+		return zet.Annotation{}, errors.New("no citation key provided")
+	}
+
+	date, err := parseAnnotationDate(field("date"))
+	if err != nil {
+		return zet.Annotation{}, err
+	}
+	a.Date = date
+
+	return a, nil
+}
+
+// parseTags splits tags, that are separated either by a semicolon (Zotero) or a comma (Readwise).
+func parseTags(s string) []string {
+	var tags []string
+	for _, tag := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == ',' }) {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+func parseAnnotationDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, errors.New("no date provided")
+	}
+	layouts := []string{
+		time.RFC3339,
+		"2006-01-02T15:04:05",
+		"2006-01-02 15:04:05",
+		"2006-01-02",
+		"January 2, 2006 3:04 PM",
+	}
+	for _, l := range layouts {
+		t, err := time.Parse(l, s)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("could not parse date %q", s)
+}
+
+func csvRecords(content string) ([]map[string]string, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(content, "\ufeff")))
+	r.FieldsPerRecord = -1
+	rows, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("parse annotations: %v", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	header := rows[0]
+	var records []map[string]string
+	for _, row := range rows[1:] {
+		record := make(map[string]string)
+		for i, value := range row {
+			if i < len(header) {
+				record[normalizeColumn(header[i])] = value
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func jsonRecords(content string) ([]map[string]string, error) {
+	var raw []map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
+		return nil, fmt.Errorf("parse annotations: %v", err)
+	}
+
+	var records []map[string]string
+	for _, r := range raw {
+		record := make(map[string]string)
+		for k, v := range r {
+			record[normalizeColumn(k)] = jsonValue(v)
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// jsonValue converts a JSON value into a string.
+// A list, e.g. of tags, is converted into a semicolon separated string.
+// Tags might also be objects like {"name": "Complexity"} (Readwise) or {"tag": "Complexity"} (Zotero).
+func jsonValue(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		var values []string
+		for _, elem := range v {
+			values = append(values, jsonValue(elem))
+		}
+		return strings.Join(values, ";")
+	case map[string]interface{}:
+		for _, k := range []string{"name", "tag"} {
+			if s, ok := v[k].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// normalizeColumn removes upper case, spaces, underscores and dashes from a column name,
+// e.g. "Citation Key" becomes "citationkey".
+func normalizeColumn(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s)
+}
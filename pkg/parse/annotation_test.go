@@ -0,0 +1,88 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+	"time"
+)
+
+func TestAnnotations(t *testing.T) {
+	var tcs = []struct {
+		content     string
+		format      string
+		annotations []zet.Annotation
+		errMsg      string
+	}{
+		// A CSV export, e.g. from Zotero. Tags are separated by a semicolon.
+		{`Citation Key,Annotation Text,Annotation Comment,Annotation Page,Manual Tags,Date Added
+welter2011,Complexity grows.,My own thought.,243,Complexity; Design,2021-08-11 10:15:00`,
+			"csv",
+			[]zet.Annotation{{
+				Text:     "Complexity grows.",
+				Comment:  "My own thought.",
+				Bibkey:   "welter2011",
+				Location: "243",
+				Tags:     []string{"Complexity", "Design"},
+				Date:     time.Date(2021, 8, 11, 10, 15, 0, 0, time.UTC),
+			}},
+			""},
+
+		// A CSV export, e.g. from Readwise with an additional column for the citation key.
+		// Tags are separated by a comma.
+		{`Highlight,Book Title,Note,Tags,Location,Highlighted at,Citekey
+"Entropy, again.",Some Book,,"Entropy, Physics",12,2021-08-11 10:15:00,shannon1948`,
+			"csv",
+			[]zet.Annotation{{
+				Text:     "Entropy, again.",
+				Bibkey:   "shannon1948",
+				Location: "12",
+				Tags:     []string{"Entropy", "Physics"},
+				Date:     time.Date(2021, 8, 11, 10, 15, 0, 0, time.UTC),
+			}},
+			""},
+
+		// A JSON export. Tags can also be objects, pages can be numbers.
+		{`[{"citationKey": "welter2011", "text": "Complexity grows.", "page": 243,
+			"tags": [{"name": "Complexity"}, {"name": "Design"}], "dateAdded": "2021-08-11T10:15:00Z"}]`,
+			"json",
+			[]zet.Annotation{{
+				Text:     "Complexity grows.",
+				Bibkey:   "welter2011",
+				Location: "243",
+				Tags:     []string{"Complexity", "Design"},
+				Date:     time.Date(2021, 8, 11, 10, 15, 0, 0, time.UTC),
+			}},
+			""},
+
+		// Every annotation needs a citation key, so that it can be referenced.
+		{`[{"text": "Complexity grows.", "dateAdded": "2021-08-11"}]`,
+			"json",
+			nil,
+			"parse annotations: annotation 1: no citation key provided"},
+
+		// Every annotation needs a date, since the id of a zettel is built from the date.
+		{"Citation Key,Annotation Text\nwelter2011,Complexity grows.",
+			"csv",
+			nil,
+			"parse annotations: annotation 1: no date provided"},
+
+		// Only CSV and JSON are supported.
+		{"", "xml", nil, "parse annotations: unknown format \"xml\", should be csv or json"},
+	}
+
+	for _, tc := range tcs {
+		got, err := Annotations(tc.content, tc.format)
+		if diff := cmp.Diff(tc.annotations, got); diff != "" {
+			t.Errorf(diff)
+		}
+
+		var errMsg string
+		if err != nil {
+			errMsg = err.Error()
+		}
+		if errMsg != tc.errMsg {
+			t.Errorf("Expected `%s`, got `%s`", tc.errMsg, errMsg)
+		}
+	}
+}
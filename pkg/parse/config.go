@@ -0,0 +1,187 @@
+package parse
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DefaultBibkeyPattern is the format AUTHORYEAR in lower case letters with an optional lower case letter,
+// e.g. "welter2011" or "shannon1948c".
+const DefaultBibkeyPattern = `^[a-z]+\d{4}[a-z]?$`
+
+// DefaultMaxIdsPerTopic follows Luhmann, whose index listed at most four ids per topic.
+const DefaultMaxIdsPerTopic = 4
+
+// DefaultMaxTopicsPerId is the number of topics an id can be listed under before it gets reported.
+const DefaultMaxTopicsPerId = 3
+
+// settings maps the name of a setting in the form "section.name" to the function applying its value.
+var settings = map[string]func(c *zet.Config, value string) error{
+	"references.bibkey pattern": func(c *zet.Config, value string) error {
+		if _, err := regexp.Compile(value); err != nil {
+			return fmt.Errorf("invalid bibkey pattern %q", value)
+		}
+		c.BibkeyPattern = value
+		return nil
+	},
+	"index.max ids per topic": func(c *zet.Config, value string) error {
+		return setPositiveInt(&c.MaxIdsPerTopic, value)
+	},
+	"index.max topics per id": func(c *zet.Config, value string) error {
+		return setPositiveInt(&c.MaxTopicsPerId, value)
+	},
+	"ids.start year": func(c *zet.Config, value string) error {
+		return setPositiveInt(&c.StartYear, value)
+	},
+	"views.link strategy": func(c *zet.Config, value string) error {
+		s := zet.LinkStrategy(strings.ToLower(value))
+		switch s {
+		case zet.HardlinkStrategy, zet.SymlinkStrategy, zet.CopyStrategy, zet.URLShortcutStrategy, zet.DesktopShortcutStrategy:
+			c.LinkStrategy = s
+			return nil
+		}
+		return fmt.Errorf("invalid link strategy %q, should be hardlink, symlink, copy, url or desktop", value)
+	},
+}
+
+func setPositiveInt(setting *int, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("invalid number %q, should be a positive number", value)
+	}
+	*setting = n
+	return nil
+}
+
+// setRule sets the severity of the rule, which is one of error, warning or off.
+func setRule(c *zet.Config, rule, value string) error {
+	if !isRule(rule, zet.Rules) {
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+	s := zet.Severity(strings.ToLower(value))
+	switch s {
+	case zet.ErrorSeverity, zet.WarningSeverity, zet.OffSeverity:
+	default:
+		return fmt.Errorf("invalid severity %q of rule %q, should be error, warning or off", value, rule)
+	}
+	if c.Rules == nil {
+		c.Rules = make(map[string]zet.Severity)
+	}
+	c.Rules[rule] = s
+	return nil
+}
+
+// addSuppression adds the suppression in the form 'rule id = reason'.
+func addSuppression(c *zet.Config, name, reason string) error {
+	f := strings.Fields(name)
+	if len(f) != 2 {
+		return fmt.Errorf("could not parse suppression %q, should be 'rule id = reason'", name)
+	}
+	rule, id := f[0], f[1]
+	if !isRule(rule, zet.Rules) {
+		return fmt.Errorf("unknown rule %q", rule)
+	}
+	if !isRule(rule, zet.IdRules) {
+		return fmt.Errorf("rule %q can't be suppressed for an id, only turned off in [rules]", rule)
+	}
+	if !IsId(id) {
+		return fmt.Errorf("invalid id %q in suppression of rule %q", id, rule)
+	}
+	if reason == "" {
+		return fmt.Errorf("suppression of rule %q for id %v has no reason", rule, id)
+	}
+	c.Suppressions = append(c.Suppressions, zet.Suppression{Rule: rule, Id: id, Reason: reason})
+	return nil
+}
+
+func isRule(rule string, rules []string) bool {
+	for _, r := range rules {
+		if r == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Config parses the content of a config file into the settings of your zettelkasten.
+// Settings that are not provided keep their default value, so an empty content returns the default config.
+// It returns all parsing errors that occurred while parsing each line.
+//
+// A config file consists of sections with settings and comments starting with '#', e.g.
+//
+//	# Bibkeys without a letter at the end, e.g. "welter2011"
+//	[references]
+//	bibkey pattern = ^[a-z]+\d{4}$
+//
+//	[index]
+//	max ids per topic = 4
+//	max topics per id = 3
+//
+//	# Ids dated before are typos
+//	[ids]
+//	start year = 2015
+//
+//	# Symlinks, since hardlinks get uploaded twice by my sync tool
+//	[views]
+//	link strategy = symlink
+//
+//	# The severity of rules: error, warning or off
+//	[rules]
+//	index-overlap = off
+//	uncited-bibkey = error
+//
+//	# Known inconsistencies in the form 'rule id = reason'
+//	[suppress]
+//	filename-format 170224a = old scan without keywords
+func Config(content string) (zet.Config, []zet.InconErr) {
+	config := zet.Config{
+		BibkeyPattern:  DefaultBibkeyPattern,
+		MaxIdsPerTopic: DefaultMaxIdsPerTopic,
+		MaxTopicsPerId: DefaultMaxTopicsPerId,
+		LinkStrategy:   zet.HardlinkStrategy,
+	}
+
+	var parseErrs []zet.InconErr
+	var section string
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.ToLower(strings.TrimSpace(line[1 : len(line)-1]))
+			continue
+		}
+
+		i := strings.Index(line, "=")
+		if i == -1 {
+			parseErrs = append(parseErrs, zet.InconErr{Message: fmt.Errorf("config: line %d: could not parse %q, should be 'name = value'", n+1, line), Rule: zet.ConfigSyntaxRule, Severity: zet.ErrorSeverity})
+			continue
+		}
+		name := strings.ToLower(strings.Join(strings.Fields(line[:i]), " "))
+		value := strings.TrimSpace(line[i+1:])
+
+		var err error
+		switch section {
+		case "rules":
+			err = setRule(&config, name, value)
+		case "suppress":
+			err = addSuppression(&config, name, value)
+		default:
+			apply, ok := settings[section+"."+name]
+			if !ok {
+				parseErrs = append(parseErrs, zet.InconErr{Message: fmt.Errorf("config: line %d: unknown setting %q in section [%v]", n+1, name, section), Rule: zet.ConfigSyntaxRule, Severity: zet.ErrorSeverity})
+				continue
+			}
+			err = apply(&config, value)
+		}
+		if err != nil {
+			parseErrs = append(parseErrs, zet.InconErr{Message: fmt.Errorf("config: line %d: %v", n+1, err), Rule: zet.ConfigSyntaxRule, Severity: zet.ErrorSeverity})
+		}
+	}
+
+	return config, parseErrs
+}
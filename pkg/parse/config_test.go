@@ -0,0 +1,92 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+var defaultConfig = zet.Config{
+	BibkeyPattern:  DefaultBibkeyPattern,
+	MaxIdsPerTopic: DefaultMaxIdsPerTopic,
+	MaxTopicsPerId: DefaultMaxTopicsPerId,
+	LinkStrategy:   zet.HardlinkStrategy,
+}
+
+func TestConfig(t *testing.T) {
+	var tcs = []struct {
+		name      string
+		content   string
+		config    zet.Config
+		parseErrs []string
+	}{
+		{
+			name:    "No config provided",
+			content: "",
+			config:  defaultConfig,
+		},
+		{
+			name:    "Setting with comments and blank lines",
+			content: "# Bibkeys like \"Welter:2011\"\n\n[References]\n  Bibkey   Pattern = ^[A-Z][a-z]+:\\d{4}$\n",
+			config:  zet.Config{BibkeyPattern: `^[A-Z][a-z]+:\d{4}$`, MaxIdsPerTopic: 4, MaxTopicsPerId: 3, LinkStrategy: zet.HardlinkStrategy},
+		},
+		{
+			name:    "Errors keep the default values",
+			content: "[references]\nbibkey pattern = [a-z\nbibkey pattern\n[index]\nbibkey pattern = .*",
+			config:  defaultConfig,
+			parseErrs: []string{
+				`config: line 2: invalid bibkey pattern "[a-z"`,
+				`config: line 3: could not parse "bibkey pattern", should be 'name = value'`,
+				`config: line 5: unknown setting "bibkey pattern" in section [index]`,
+			},
+		},
+		{
+			name:    "Start year",
+			content: "[ids]\nstart year = 2015\n",
+			config:  zet.Config{BibkeyPattern: DefaultBibkeyPattern, MaxIdsPerTopic: 4, MaxTopicsPerId: 3, LinkStrategy: zet.HardlinkStrategy, StartYear: 2015},
+		},
+		{
+			name:    "Rules and suppressions",
+			content: "[rules]\nindex-overlap = off\nUncited-Bibkey = Error\n\n# Old scans\n[suppress]\nfilename-format 170224a = old scan without keywords\n",
+			config: zet.Config{
+				BibkeyPattern:  DefaultBibkeyPattern,
+				MaxIdsPerTopic: DefaultMaxIdsPerTopic,
+				MaxTopicsPerId: DefaultMaxTopicsPerId,
+				LinkStrategy:   zet.HardlinkStrategy,
+				Rules:          map[string]zet.Severity{"index-overlap": zet.OffSeverity, "uncited-bibkey": zet.ErrorSeverity},
+				Suppressions:   []zet.Suppression{{Rule: "filename-format", Id: "170224a", Reason: "old scan without keywords"}},
+			},
+		},
+		{
+			name:    "Invalid rules and suppressions",
+			content: "[rules]\nno-such-rule = off\ncycle = info\n[suppress]\ncycle = reason\ncycle 1702 = reason\ncycle 170224a =\nno-such-rule 170224a = reason\nuncited-bibkey 170224a = reason\ndead-link 170224a = reason",
+			config:  defaultConfig,
+			parseErrs: []string{
+				`config: line 2: unknown rule "no-such-rule"`,
+				`config: line 3: invalid severity "info" of rule "cycle", should be error, warning or off`,
+				`config: line 5: could not parse suppression "cycle", should be 'rule id = reason'`,
+				`config: line 6: invalid id "1702" in suppression of rule "cycle"`,
+				`config: line 7: suppression of rule "cycle" for id 170224a has no reason`,
+				`config: line 8: unknown rule "no-such-rule"`,
+				`config: line 9: rule "uncited-bibkey" can't be suppressed for an id, only turned off in [rules]`,
+				`config: line 10: rule "dead-link" can't be suppressed for an id, only turned off in [rules]`,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			config, parseErrs := Config(tc.content)
+			if diff := cmp.Diff(tc.config, config); diff != "" {
+				t.Errorf(diff)
+			}
+			var errs []string
+			for _, e := range parseErrs {
+				errs = append(errs, e.Error())
+			}
+			if diff := cmp.Diff(tc.parseErrs, errs); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
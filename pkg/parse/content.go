@@ -3,6 +3,7 @@ package parse
 import (
 	"errors"
 	"github.com/crelder/zet"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -15,7 +16,7 @@ func Content(content string, zettel []zet.Zettel) (string, error) {
 		return "", err
 	}
 
-	fn, err := toFilename(z)
+	fn, err := Format(z)
 	if err != nil {
 		return "", err
 	}
@@ -25,35 +26,66 @@ func Content(content string, zettel []zet.Zettel) (string, error) {
 
 // toZettel parses the content of a zettel into a zettel instance.
 func toZettel(content string, zettel []zet.Zettel) (zet.Zettel, error) {
-	var z zet.Zettel
 	if content == "" {
 		return zet.Zettel{}, errors.New("parse.ToZettel: cannot parse empty content string")
 	}
 
+	z, date, err := Header(content)
+	if err != nil {
+		return zet.Zettel{}, err
+	}
+	z.Keywords = nonEmpty(z.Keywords)
+	if len(z.Keywords) == 0 {
+		return zet.Zettel{}, errors.New("at least one keyword is needed for creation of filename")
+	}
+
+	id, err2 := generateId(date, z.Keywords, zettel)
+	if err2 != nil {
+		return zet.Zettel{}, err2
+	}
+	z.Id = id
+
+	return z, nil
+}
+
+// Header parses the header of the content of a zettel, as it is written when importing zettel, e.g.
+//
+//	Keyword1, Keyword2
+//	24.2.17
+//	Context, welter2011 12, 170223b
+//
+// It returns the keywords, contexts, references and predecessor as zettel without an id and the date.
+// If the second line is no date, the content has no header and an error is returned.
+func Header(content string) (zet.Zettel, time.Time, error) {
+	var z zet.Zettel
 	header := getHeader(content)
 
 	date, err := parseDate(header.date)
 	if err != nil {
-		return zet.Zettel{}, err
+		return zet.Zettel{}, time.Time{}, err
 	}
 
 	z.Keywords = parseKeywordsFromHeader(header.keywords)
 
 	con, err := parseContext(header.contexts)
 	if err != nil {
-		return zet.Zettel{}, err
+		return zet.Zettel{}, time.Time{}, err
 	}
 	z.Predecessor = con.Predecessor
 	z.References = con.References
 	z.Context = con.Context
 
-	id, err2 := generateId(date, z.Keywords, zettel)
-	if err2 != nil {
-		return zet.Zettel{}, err2
-	}
-	z.Id = id
+	return z, date, nil
+}
 
-	return z, nil
+// dateShape matches a line in the form of a date of a header, e.g. "24.2.17", "170224", "02/24/17" or "February 24, 2017",
+// even if it is no valid date like "32.2.17".
+var dateShape = regexp.MustCompile(`^(\d{1,2}\.\d{1,2}\.(\d{2}|\d{4})|\d{6}|\d{2}/\d{2}/\d{2}|[A-Za-z]+ \d{1,2}, \d{4})$`)
+
+// HasHeader checks if the content of a zettel starts with a header, as it is written when importing zettel:
+// the second line is in the form of a date. The header can still be malformed, e.g. with an invalid date.
+func HasHeader(content string) bool {
+	return dateShape.MatchString(getHeader(content).date)
 }
 
 // generateId returns a valid, unique id for a zettel (therefore the id does not exist in the zettelkasten yet).
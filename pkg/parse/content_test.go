@@ -52,6 +52,13 @@ func TestContent(t *testing.T) {
 		// which then can be parsed into a filename.
 		{"", "", "parse.ToZettel: cannot parse empty content string"},
 
+		// The keywords are needed for the filename and the id.
+		{"\n12.1.2020\nropohl2013a 14\n\nThe keywords are missing", "", "at least one keyword is needed for creation of filename"},
+		{" , \n12.1.2020", "", "at least one keyword is needed for creation of filename"},
+
+		// Keywords start with a capital letter.
+		{"modelle, Theorien\n12.1.2020", "200112m - Modelle, Theorien.txt", ""},
+
 		// A date is missing, which is used for defining the id.
 		{"Risiko, Unsicherheit\nPaul Ehrlich, 200812c, 181201f, kahn1985 12", "", "parseDate: could not parse date"},
 	}
@@ -70,3 +77,24 @@ func TestContent(t *testing.T) {
 		}
 	}
 }
+
+func TestHasHeader(t *testing.T) {
+	tcs := []struct {
+		content string
+		want    bool
+	}{
+		{"Modelle, Theorien\n12.1.2020\nropohl2013a 14\n\nText", true},
+		{"Modelle\n200112", true},
+		{"Modelle\nJanuary 12, 2020", true},
+		// A header with an invalid date is still a header.
+		{"Modelle\n32.1.2020", true},
+		{"A zettel without a header\nstarts with its text.", false},
+		{"Modelle", false},
+	}
+
+	for _, tc := range tcs {
+		if got := HasHeader(tc.content); got != tc.want {
+			t.Errorf("HasHeader(%q) = %v, want %v", tc.content, got, tc.want)
+		}
+	}
+}
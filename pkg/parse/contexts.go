@@ -0,0 +1,81 @@
+package parse
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"strings"
+)
+
+// Contexts parses the content of a registry of contexts, where every line declares a context with its type,
+// its name and optional aliases in brackets. Lines starting with '#' are comments, e.g.
+//
+//	# People I talk to
+//	Person: Anna Müller (Anna, A. Müller)
+//	Movie: Dunkirk
+//
+// It returns all parsing errors that occurred while parsing each line, including names or aliases that
+// are declared more than once.
+func Contexts(content string) ([]zet.ContextEntry, []zet.InconErr) {
+	var contexts []zet.ContextEntry
+	var parseErrs []zet.InconErr
+	declared := make(map[string]bool)
+
+	for n, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		c, err := contextEntry(line)
+		if err != nil {
+			parseErrs = append(parseErrs, zet.InconErr{Message: fmt.Errorf("contexts: line %d: %v", n+1, err), Rule: zet.ContextsSyntaxRule, Severity: zet.ErrorSeverity})
+			continue
+		}
+
+		var duplicate bool
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			if declared[strings.ToLower(name)] {
+				parseErrs = append(parseErrs, zet.InconErr{Message: fmt.Errorf("contexts: line %d: %q already declared", n+1, name), Rule: zet.ContextsSyntaxRule, Severity: zet.ErrorSeverity})
+				duplicate = true
+			}
+			declared[strings.ToLower(name)] = true
+		}
+		if !duplicate {
+			contexts = append(contexts, c)
+		}
+	}
+
+	return contexts, parseErrs
+}
+
+// contextEntry parses a line like "Person: Anna Müller (Anna)".
+func contextEntry(line string) (zet.ContextEntry, error) {
+	i := strings.Index(line, ":")
+	if i == -1 {
+		return zet.ContextEntry{}, fmt.Errorf("could not parse %q, should be 'Type: Name (Alias, ...)'", line)
+	}
+	c := zet.ContextEntry{Type: strings.TrimSpace(line[:i])}
+	name := strings.TrimSpace(line[i+1:])
+
+	if open := strings.Index(name, "("); open != -1 {
+		if !strings.HasSuffix(name, ")") {
+			return zet.ContextEntry{}, fmt.Errorf("missing closing ')' in %q", line)
+		}
+		for _, a := range strings.Split(name[open+1:len(name)-1], ",") {
+			if a = strings.TrimSpace(a); a != "" {
+				c.Aliases = append(c.Aliases, a)
+			}
+		}
+		name = strings.TrimSpace(name[:open])
+	}
+	c.Name = name
+
+	if c.Type == "" || c.Name == "" {
+		return zet.ContextEntry{}, fmt.Errorf("could not parse %q, type and name must not be empty", line)
+	}
+	// Type and name become folder names in the folder 'CONTEXTS'.
+	if strings.Contains(c.Type+c.Name, "/") {
+		return zet.ContextEntry{}, fmt.Errorf("type and name in %q must not contain '/'", line)
+	}
+	return c, nil
+}
@@ -0,0 +1,44 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestContexts(t *testing.T) {
+	content := `# People
+Person: Anna Müller (Anna, A. Müller)
+Movie:Dunkirk
+
+Anna Müller
+Person: Anna (Annie)
+Place: Berlin (Berlin-Mitte
+Event: 
+Place: Paris/France`
+
+	wantContexts := []zet.ContextEntry{
+		{Type: "Person", Name: "Anna Müller", Aliases: []string{"Anna", "A. Müller"}},
+		{Type: "Movie", Name: "Dunkirk"},
+	}
+	wantErrs := []string{
+		`contexts: line 5: could not parse "Anna Müller", should be 'Type: Name (Alias, ...)'`,
+		`contexts: line 6: "Anna" already declared`,
+		`contexts: line 7: missing closing ')' in "Place: Berlin (Berlin-Mitte"`,
+		`contexts: line 8: could not parse "Event:", type and name must not be empty`,
+		`contexts: line 9: type and name in "Place: Paris/France" must not contain '/'`,
+	}
+
+	contexts, parseErrs := Contexts(content)
+
+	if diff := cmp.Diff(wantContexts, contexts); diff != "" {
+		t.Errorf(diff)
+	}
+	var errs []string
+	for _, e := range parseErrs {
+		errs = append(errs, e.Error())
+	}
+	if diff := cmp.Diff(wantErrs, errs); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -7,55 +7,226 @@ import (
 	"strings"
 )
 
-// Index parses the content of an index.
+// Index parses the content of an index, where every topic is followed by a colon and one or more ids, e.g.
+//
+//	# Lines starting with '#' are comments.
+//	Complexity: 190119e, 220122a
+//	"Programming: Go": 170224a,
+//		210328obj, 220115p
+//	Thermodynamics: 170213d; see Entropy, "Programming: Go"
+//	Heat: see Thermodynamics
+//
+// Topics containing a colon must be quoted. Indented lines without a colon continue
+// the ids of the topic above. After the ids, "; see" refers to other topics. A topic referring
+// to other topics doesn't need ids, e.g. for synonyms. The order of the topics is preserved.
+//
 // It returns all parsing errors that occurred while parsing each line.
 func Index(content string) (zet.Index, []zet.InconErr) {
 	var parsErrs []zet.InconErr
 	if content == "" {
-		parsErrs = append(parsErrs, zet.InconErr{Message: errors.New("parse Index: index is empty")})
+		parsErrs = append(parsErrs, zet.InconErr{Message: errors.New("parse Index: index is empty"), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
 		return nil, parsErrs
 	}
-	result := make(map[string][]string)
 
-	lines := strings.Split(content, "\n")
-out:
-	for lineNumber, line := range lines {
-		if line == "" {
+	var result zet.Index
+	definedIn := make(map[string]int)
+	var entry *zet.IndexEntry // the topic whose ids are currently parsed
+	var valid bool            // whether all lines of the current topic could be parsed
+
+	// finish adds the current topic to the result if all its lines could be parsed.
+	finish := func() {
+		if entry == nil {
+			return
+		}
+		if valid && len(entry.Ids) == 0 && len(entry.SeeAlso) == 0 {
+			parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: no ids provided for topic %q", entry.Line, entry.Topic), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+			valid = false
+		}
+		if valid {
+			result = append(result, *entry)
+		}
+		entry = nil
+	}
+
+	for n, line := range strings.Split(content, "\n") {
+		lineNumber := n + 1
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
 			continue
 		}
-		index := strings.Split(line, ":")
-		if len(index) != 2 {
-			parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: could not parse line %q", line)})
+
+		// A continuation line adds ids to the topic above.
+		if isContinuation(line) {
+			if entry == nil {
+				parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: ids %q do not belong to a topic", lineNumber, trimmed), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+				continue
+			}
+			ids, seeAlso, err := parseIds(trimmed)
+			if err != nil {
+				parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: %v", lineNumber, err), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+				valid = false
+				continue
+			}
+			entry.Ids = append(entry.Ids, ids...)
+			entry.SeeAlso = append(entry.SeeAlso, seeAlso...)
 			continue
 		}
-		topic := strings.TrimSpace(index[0])
-		ids := strings.Split(index[1], ",")
 
-		if len(ids) == 1 && strings.TrimSpace(ids[0]) == "" { // TODO: make so that all potential positions get cleaned
-			parsErrs = append(parsErrs, zet.InconErr{fmt.Errorf("index: could not parse line %q, no ids provided", line)})
+		finish()
+
+		topic, rest, err := parseTopic(trimmed)
+		if err != nil {
+			parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: %v", lineNumber, err), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+			// The continuation lines of this topic are skipped.
+			entry, valid = &zet.IndexEntry{}, false
 			continue
 		}
+		if l, ok := definedIn[topic]; ok {
+			parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: topic %q already defined in line %d", lineNumber, topic, l), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+			entry, valid = &zet.IndexEntry{}, false
+			continue
+		}
+		definedIn[topic] = lineNumber
 
-		for _, id := range ids {
-			if parseId(strings.TrimSpace(id)) == "" {
-				parsErrs = append(
-					parsErrs,
-					zet.InconErr{fmt.Errorf("index: could not parse line %v, not an id %q", lineNumber, id)})
-				continue out
-			}
+		entry, valid = &zet.IndexEntry{Topic: topic, Line: lineNumber}, true
+		ids, seeAlso, err := parseIds(rest)
+		if err != nil {
+			parsErrs = append(parsErrs, zet.InconErr{Message: fmt.Errorf("index: line %d: %v", lineNumber, err), Rule: zet.IndexSyntaxRule, Severity: zet.ErrorSeverity})
+			valid = false
+			continue
+		}
+		entry.Ids, entry.SeeAlso = ids, seeAlso
+	}
+	finish()
+
+	return result, parsErrs
+}
+
+// isContinuation checks if the line continues the ids of the topic above, i.e. it is indented and
+// neither a comment nor a topic.
+func isContinuation(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, `"`) {
+		return false
+	}
+	isIndented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+	return isIndented && !strings.Contains(idsPart(trimmed), ":")
+}
+
+// idsPart returns the part of s before the topics it refers to, since quoted topics may contain a colon.
+func idsPart(s string) string {
+	if end := strings.Index(s, ";"); end != -1 {
+		return s[:end]
+	}
+	if isSeeAlso(s) {
+		return ""
+	}
+	return s
+}
+
+// parseTopic splits a line like `Complexity: 190119e` or `"Programming: Go": 170224a` into
+// the topic and the rest of the line after the colon.
+func parseTopic(line string) (string, string, error) {
+	if strings.HasPrefix(line, `"`) {
+		end := strings.Index(line[1:], `"`)
+		if end == -1 {
+			return "", "", fmt.Errorf("missing closing '\"' in %q", line)
+		}
+		topic := line[1 : end+1]
+		rest := strings.TrimSpace(line[end+2:])
+		if !strings.HasPrefix(rest, ":") || topic == "" {
+			return "", "", fmt.Errorf("could not parse %q, should be '\"topic\": ids'", line)
 		}
+		return topic, rest[1:], nil
+	}
 
-		var trimmedIds []string
-		for _, id := range ids {
-			trimmedIds = append(trimmedIds, strings.TrimSpace(id))
+	i := strings.Index(line, ":")
+	if i == -1 {
+		return "", "", fmt.Errorf("could not parse %q, should be 'topic: ids'", line)
+	}
+	topic, rest := strings.TrimSpace(line[:i]), line[i+1:]
+	if strings.Contains(idsPart(rest), ":") {
+		return "", "", fmt.Errorf("could not parse %q, quote topics containing ':'", line)
+	}
+	if topic == "" {
+		return "", "", fmt.Errorf("could not parse %q, no topic provided", line)
+	}
+	return topic, rest, nil
+}
+
+// parseIds parses a comma separated list of ids, optionally followed by the topics it refers to, e.g.
+// "170213d, 180112a; see Entropy, Information". A trailing comma before a continuation line is allowed.
+func parseIds(s string) ([]string, []string, error) {
+	var seeAlso []string
+	if i := strings.Index(s, ";"); i != -1 || isSeeAlso(s) {
+		if i == -1 {
+			i = 0
+		}
+		var err error
+		seeAlso, err = parseSeeAlso(strings.TrimSpace(strings.TrimPrefix(s[i:], ";")))
+		if err != nil {
+			return nil, nil, err
 		}
+		s = s[:i]
+	}
 
-		result[topic] = trimmedIds
+	var ids []string
+	for _, id := range strings.Split(s, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" {
+			continue
+		}
+		if parseId(id) != id {
+			return nil, nil, fmt.Errorf("not an id %q", id)
+		}
+		ids = append(ids, id)
 	}
+	return ids, seeAlso, nil
+}
 
-	if len(result) == 0 {
-		return nil, parsErrs
+// isSeeAlso checks if s starts with "see", e.g. "see Entropy" or "see also Entropy".
+func isSeeAlso(s string) bool {
+	fields := strings.Fields(s)
+	return len(fields) > 0 && strings.EqualFold(fields[0], "see")
+}
+
+// parseSeeAlso parses references to other topics like `see Entropy, "Programming: Go"` or `see also Entropy`.
+// Topics containing a comma or a colon must be quoted.
+func parseSeeAlso(s string) ([]string, error) {
+	if !isSeeAlso(s) {
+		return nil, fmt.Errorf("could not parse %q, should be 'see topic, ...'", s)
+	}
+	s = strings.TrimSpace(s[len("see"):])
+	if fields := strings.Fields(s); len(fields) > 0 && strings.EqualFold(fields[0], "also") {
+		s = strings.TrimSpace(s[len("also"):])
 	}
 
-	return result, parsErrs
+	var topics []string
+	for s != "" {
+		var topic string
+		if strings.HasPrefix(s, `"`) {
+			end := strings.Index(s[1:], `"`)
+			if end == -1 {
+				return nil, fmt.Errorf("missing closing '\"' in %q", s)
+			}
+			topic, s = s[1:end+1], strings.TrimSpace(s[end+2:])
+			if s != "" && !strings.HasPrefix(s, ",") {
+				return nil, fmt.Errorf("could not parse %q, topics must be separated by ','", s)
+			}
+		} else {
+			end := strings.Index(s, ",")
+			if end == -1 {
+				end = len(s)
+			}
+			topic, s = strings.TrimSpace(s[:end]), s[end:]
+		}
+		s = strings.TrimSpace(strings.TrimPrefix(s, ","))
+		if topic != "" {
+			topics = append(topics, topic)
+		}
+	}
+	if len(topics) == 0 {
+		return nil, fmt.Errorf("no topic provided after 'see'")
+	}
+	return topics, nil
 }
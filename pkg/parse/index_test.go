@@ -18,47 +18,89 @@ func TestParseIndex(t *testing.T) {
 
 		// Simple index with one entry "Leben", which has only one reference id.
 		{"Leben: 170713a",
-			map[string][]string{"Leben": {"170713a"}},
+			zet.Index{{Topic: "Leben", Ids: []string{"170713a"}, Line: 1}},
 			""},
 
 		// Simple index with one entry "Leben", which has two reference ids.
 		{"Leben: 170713a, 201104c",
-			map[string][]string{"Leben": {"170713a", "201104c"}},
+			zet.Index{{Topic: "Leben", Ids: []string{"170713a", "201104c"}, Line: 1}},
 			""},
 
 		// Index with two entries "Leben" and "Programmierung, Objektorientiert",
-		// both with two reference ids.
+		// both with two reference ids. The order of the topics is preserved.
 		{`Leben: 170713a, 201104c
   				Programmierung, Objektorientiert: 220130f, 120412e`,
-			map[string][]string{"Leben": {"170713a", "201104c"},
-				"Programmierung, Objektorientiert": {"220130f", "120412e"}},
+			zet.Index{
+				{Topic: "Leben", Ids: []string{"170713a", "201104c"}, Line: 1},
+				{Topic: "Programmierung, Objektorientiert", Ids: []string{"220130f", "120412e"}, Line: 2},
+			},
 			"",
 		},
 
 		// Not providing any space should work also.
 		{"Leben:170713a,210404d",
-			map[string][]string{"Leben": {"170713a", "210404d"}},
+			zet.Index{{Topic: "Leben", Ids: []string{"170713a", "210404d"}, Line: 1}},
+			""},
+
+		// Comments, empty lines and indented continuation lines, also with a trailing comma.
+		{`# Topics of my life
+Leben: 170713a,
+	201104c
+    210404d, 210328obj
+
+# Topics of my work
+Zettelkasten:
+	220130f`,
+			zet.Index{
+				{Topic: "Leben", Ids: []string{"170713a", "201104c", "210404d", "210328obj"}, Line: 2},
+				{Topic: "Zettelkasten", Ids: []string{"220130f"}, Line: 7},
+			},
+			""},
+
+		// Topics containing a colon must be quoted.
+		{`"Programmierung: Go": 170713a`,
+			zet.Index{{Topic: "Programmierung: Go", Ids: []string{"170713a"}, Line: 1}},
 			""},
 
 		// Invalid id provided should return an error.
 		{"Leben:170a",
 			nil,
-			"index: could not parse line 0, not an id \"170a\""},
+			"index: line 1: not an id \"170a\""},
+
+		// An invalid continuation line invalidates the whole topic, but not the following topics.
+		{"Leben: 170713a\n  170a\nArbeit: 201104c",
+			zet.Index{{Topic: "Arbeit", Ids: []string{"201104c"}, Line: 3}},
+			"index: line 2: not an id \"170a\""},
 
 		// Wrong format due to two columns ("::"), which should return an error.
 		{"Leben::170713a",
 			nil,
-			"index: could not parse line \"Leben::170713a\""},
+			"index: line 1: could not parse \"Leben::170713a\", quote topics containing ':'"},
 
 		// Wrong format due to missing column (":"), which should return an error.
 		{"Leben 170713a",
 			nil,
-			"index: could not parse line \"Leben 170713a\""},
+			"index: line 1: could not parse \"Leben 170713a\", should be 'topic: ids'"},
+
+		// A quoted topic must be closed.
+		{"\"Leben: 170713a",
+			nil,
+			"index: line 1: missing closing '\"' in \"\\\"Leben: 170713a\""},
 
 		// No ids provided, should return an error.
 		{"Leben:",
 			nil,
-			"index: could not parse line \"Leben:\", no ids provided"},
+			"index: line 1: no ids provided for topic \"Leben\""},
+
+		// A continuation line needs a topic above.
+		{"  170713a",
+			nil,
+			"index: line 1: ids \"170713a\" do not belong to a topic"},
+
+		// A topic can be defined only once.
+		{"Leben: 170713a\nLeben: 201104c",
+			zet.Index{{Topic: "Leben", Ids: []string{"170713a"}, Line: 1}},
+			"index: line 2: topic \"Leben\" already defined in line 1"},
 	}
 
 	for _, tc := range tcs {
@@ -77,3 +119,37 @@ func TestParseIndex(t *testing.T) {
 		}
 	}
 }
+
+func TestParseIndexSeeAlso(t *testing.T) {
+	input := `Thermodynamics: 170213d; see Entropy, Information
+Heat: see Thermodynamics
+  "Heat: Radiation": see "Programming: C"
+"Programming: Go": 170224a,
+	210328obj; see also "Programming, Objektorientiert", "Programming: C"
+Entropy: 180112a
+	see Information
+Information: 190119e; Thermodynamics`
+
+	want := zet.Index{
+		{Topic: "Thermodynamics", Ids: []string{"170213d"}, SeeAlso: []string{"Entropy", "Information"}, Line: 1},
+		{Topic: "Heat", SeeAlso: []string{"Thermodynamics"}, Line: 2},
+		{Topic: "Heat: Radiation", SeeAlso: []string{"Programming: C"}, Line: 3},
+		{Topic: "Programming: Go", Ids: []string{"170224a", "210328obj"}, SeeAlso: []string{"Programming, Objektorientiert", "Programming: C"}, Line: 4},
+		{Topic: "Entropy", Ids: []string{"180112a"}, SeeAlso: []string{"Information"}, Line: 6},
+	}
+	wantErrs := []string{
+		`index: line 8: could not parse "Thermodynamics", should be 'see topic, ...'`,
+	}
+
+	got, parseErrs := Index(input)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+	var errs []string
+	for _, e := range parseErrs {
+		errs = append(errs, e.Error())
+	}
+	if diff := cmp.Diff(wantErrs, errs); diff != "" {
+		t.Errorf(diff)
+	}
+}
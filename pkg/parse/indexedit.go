@@ -0,0 +1,319 @@
+package parse
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"regexp"
+	"strings"
+)
+
+// AddToIndex adds the ids to the topic in the content of an index and returns the new content.
+// If the topic doesn't exist, it is added at the end of the index.
+// Comments, the order of the topics and the formatting of all other lines are preserved.
+func AddToIndex(content, topic string, ids ...string) (string, error) {
+	for _, id := range ids {
+		if parseId(id) != id {
+			return "", fmt.Errorf("index: not an id %q", id)
+		}
+	}
+
+	return editIndex(content, func(index zet.Index, lines []string) ([]string, error) {
+		start, end, ok := findTopic(index, lines, topic)
+		if !ok {
+			newLine := formatTopic(topic) + ": " + strings.Join(ids, ", ")
+			for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+				lines = lines[:len(lines)-1]
+			}
+			return append(lines, newLine, ""), nil
+		}
+
+		existing, _ := index.Ids(topic)
+		for _, id := range ids {
+			if contains(existing, id) {
+				return nil, fmt.Errorf("index: id %v already in topic %q", id, topic)
+			}
+		}
+
+		// Add the ids to the last line holding ids, so that ids referring to other topics stay at the end.
+		n := start
+		for i := start + 1; i <= end; i++ {
+			if isContinuation(lines[i]) && splitIndexLine(lines[i], false).ids != "" {
+				n = i
+			}
+		}
+		l := splitIndexLine(lines[n], n == start)
+		l.setIds(append(l.idList(), ids...))
+		lines[n] = l.String()
+		return lines, nil
+	})
+}
+
+// RemoveFromIndex removes the ids from the topic in the content of an index and returns the new content.
+// A topic without ids and references to other topics is removed.
+// Comments, the order of the topics and the formatting of all other lines are preserved.
+func RemoveFromIndex(content, topic string, ids ...string) (string, error) {
+	return editIndex(content, func(index zet.Index, lines []string) ([]string, error) {
+		start, end, ok := findTopic(index, lines, topic)
+		if !ok {
+			return nil, fmt.Errorf("index: topic %q not found", topic)
+		}
+
+		emptied := make(map[int]bool) // continuation lines without content
+		for _, id := range ids {
+			removed := false
+			for i := start; i <= end && !removed; i++ {
+				if i > start && !isContinuation(lines[i]) {
+					continue
+				}
+				l := splitIndexLine(lines[i], i == start)
+				list := l.idList()
+				for n, e := range list {
+					if e == id {
+						l.setIds(append(list[:n:n], list[n+1:]...))
+						lines[i], removed = l.String(), true
+						emptied[i] = i > start && l.ids == "" && l.seeAlso == ""
+						break
+					}
+				}
+			}
+			if !removed {
+				return nil, fmt.Errorf("index: id %v not in topic %q", id, topic)
+			}
+		}
+
+		// Remove continuation lines without content and the topic if it has no content anymore.
+		var result []string
+		l := splitIndexLine(lines[start], true)
+		isEmpty := l.ids == "" && l.seeAlso == ""
+		for i := start; i <= end; i++ {
+			if emptied[i] {
+				continue
+			}
+			if i > start && isContinuation(lines[i]) {
+				isEmpty = false
+			}
+			result = append(result, lines[i])
+		}
+		if isEmpty {
+			result = nil
+			// Comments and empty lines after the topic stay.
+			for i := start + 1; i <= end; i++ {
+				if !isContinuation(lines[i]) {
+					result = append(result, lines[i])
+				}
+			}
+		}
+
+		return append(append(lines[:start:start], result...), lines[end+1:]...), nil
+	})
+}
+
+// RenameTopic renames the topic in the content of an index including all references to it and
+// returns the new content.
+// Comments, the order of the topics and the formatting of all other lines are preserved.
+func RenameTopic(content, oldTopic, newTopic string) (string, error) {
+	newTopic = strings.TrimSpace(newTopic)
+	if newTopic == "" || strings.Contains(newTopic, `"`) {
+		return "", fmt.Errorf("index: invalid topic %q", newTopic)
+	}
+
+	return editIndex(content, func(index zet.Index, lines []string) ([]string, error) {
+		start, _, ok := findTopic(index, lines, oldTopic)
+		if !ok {
+			return nil, fmt.Errorf("index: topic %q not found", oldTopic)
+		}
+		if _, exists := index.Ids(newTopic); exists {
+			return nil, fmt.Errorf("index: topic %q already exists", newTopic)
+		}
+
+		l := splitIndexLine(lines[start], true)
+		l.head = formatTopic(newTopic) + ":"
+		lines[start] = l.String()
+
+		for i, line := range lines {
+			trimmed := strings.TrimSpace(line)
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			l := splitIndexLine(line, !isContinuation(line))
+			if l.seeAlso == "" {
+				continue
+			}
+			topics, err := parseSeeAlso(l.seeAlso)
+			if err != nil || !contains(topics, oldTopic) {
+				continue
+			}
+			keyword := "see"
+			if fields := strings.Fields(l.seeAlso); len(fields) > 1 && strings.EqualFold(fields[1], "also") {
+				keyword = "see also"
+			}
+			var formatted []string
+			for _, t := range topics {
+				if t == oldTopic {
+					t = newTopic
+				}
+				formatted = append(formatted, formatSeeAlso(t))
+			}
+			l.seeAlso = keyword + " " + strings.Join(formatted, ", ")
+			lines[i] = l.String()
+		}
+		return lines, nil
+	})
+}
+
+// editIndex applies the edit to the lines of the index and makes sure, that the edit doesn't add parsing errors.
+func editIndex(content string, edit func(index zet.Index, lines []string) ([]string, error)) (string, error) {
+	index, parseErrs := Index(content)
+	lines, err := edit(index, strings.Split(content, "\n"))
+	if err != nil {
+		return "", err
+	}
+
+	edited := strings.Join(lines, "\n")
+	_, newParseErrs := Index(edited)
+	if err := addedError(parseErrs, newParseErrs); err != nil {
+		return "", fmt.Errorf("index: the edit would make your index invalid: %v", err)
+	}
+	return edited, nil
+}
+
+// lineNumber matches the line number of a parsing error of the index, which changes when lines are added or removed.
+var lineNumber = regexp.MustCompile(`line \d+: `)
+
+// addedError returns the first parsing error after the edit, that didn't exist before, or nil.
+// The errors are compared without their line number, so errors only moved by the edit are not new.
+func addedError(before, after []zet.InconErr) error {
+	existing := make(map[string]int)
+	for _, e := range before {
+		existing[lineNumber.ReplaceAllString(e.Error(), "")]++
+	}
+	for _, e := range after {
+		m := lineNumber.ReplaceAllString(e.Error(), "")
+		if existing[m] == 0 {
+			return e
+		}
+		existing[m]--
+	}
+	return nil
+}
+
+// findTopic returns the first and the last line of the topic, including its continuation lines.
+func findTopic(index zet.Index, lines []string, topic string) (int, int, bool) {
+	for _, e := range index {
+		if e.Topic != topic {
+			continue
+		}
+		start := e.Line - 1
+		end := start
+		for i := start + 1; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			if !isContinuation(lines[i]) {
+				break
+			}
+			end = i
+		}
+		return start, end, true
+	}
+	return 0, 0, false
+}
+
+// indexLine is a line of an index split into its parts, e.g. `Entropy: 180112a, 190119e; see Information` into
+// the head `Entropy:`, the ids `180112a, 190119e` and the references to other topics `see Information`.
+// Continuation lines have no head.
+type indexLine struct {
+	indent  string
+	head    string
+	ids     string
+	seeAlso string
+}
+
+func splitIndexLine(line string, isTopic bool) indexLine {
+	trimmed := strings.TrimSpace(line)
+	l := indexLine{indent: line[:len(line)-len(strings.TrimLeft(line, " \t"))]}
+
+	rest := trimmed
+	if isTopic {
+		_, r, err := parseTopic(trimmed)
+		if err != nil {
+			return indexLine{head: line}
+		}
+		l.head, rest = trimmed[:len(trimmed)-len(r)], r
+	}
+
+	if i := strings.Index(rest, ";"); i != -1 {
+		l.ids, l.seeAlso = rest[:i], rest[i+1:]
+	} else if isSeeAlso(rest) {
+		l.seeAlso = rest
+	} else {
+		l.ids = rest
+	}
+	l.ids, l.seeAlso = strings.TrimSpace(l.ids), strings.TrimSpace(l.seeAlso)
+	return l
+}
+
+func (l indexLine) idList() []string {
+	var ids []string
+	for _, id := range strings.Split(l.ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// setIds replaces the ids and keeps a trailing comma, which indicates that the ids continue on the next line.
+func (l *indexLine) setIds(ids []string) {
+	trailingComma := strings.HasSuffix(l.ids, ",")
+	l.ids = strings.Join(ids, ", ")
+	if trailingComma && l.ids != "" {
+		l.ids += ","
+	}
+}
+
+func (l indexLine) String() string {
+	s := l.indent + l.head
+	if l.ids != "" {
+		if l.head != "" {
+			s += " "
+		}
+		s += l.ids
+	}
+	if l.seeAlso != "" {
+		switch {
+		case l.ids != "":
+			s += "; "
+		case l.head != "":
+			s += " "
+		}
+		s += l.seeAlso
+	}
+	return s
+}
+
+// formatTopic quotes a topic if it contains a colon.
+func formatTopic(topic string) string {
+	if strings.Contains(topic, ":") {
+		return `"` + topic + `"`
+	}
+	return topic
+}
+
+// formatSeeAlso quotes a topic referred to if it contains a colon or a comma.
+func formatSeeAlso(topic string) string {
+	if strings.ContainsAny(topic, ":,") {
+		return `"` + topic + `"`
+	}
+	return topic
+}
+
+func contains(s []string, e string) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
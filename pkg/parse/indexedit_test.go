@@ -0,0 +1,204 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+const editIndexContent = `# Topics of my life
+Leben:170713a
+Thermodynamik: 170213d,
+	180112a
+	# Heat is a synonym
+	see Entropie
+
+Entropie: 190119e; see Thermodynamik
+Wärme: see Thermodynamik
+`
+
+func TestAddToIndex(t *testing.T) {
+	tcs := []struct {
+		name  string
+		topic string
+		ids   []string
+		want  string
+		err   string
+	}{
+		{
+			name:  "Add to a topic without formatting the other lines",
+			topic: "Leben",
+			ids:   []string{"201104c"},
+			want:  "# Topics of my life\nLeben: 170713a, 201104c\nThermodynamik: 170213d,\n\t180112a\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see Thermodynamik\nWärme: see Thermodynamik\n",
+		},
+		{
+			name:  "Add to the last continuation line with ids",
+			topic: "Thermodynamik",
+			ids:   []string{"201104c", "210404d"},
+			want:  "# Topics of my life\nLeben:170713a\nThermodynamik: 170213d,\n\t180112a, 201104c, 210404d\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see Thermodynamik\nWärme: see Thermodynamik\n",
+		},
+		{
+			name:  "Add before the references to other topics",
+			topic: "Wärme",
+			ids:   []string{"201104c"},
+			want:  "# Topics of my life\nLeben:170713a\nThermodynamik: 170213d,\n\t180112a\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see Thermodynamik\nWärme: 201104c; see Thermodynamik\n",
+		},
+		{
+			name:  "Create a topic",
+			topic: "Programmierung: Go",
+			ids:   []string{"201104c"},
+			want:  editIndexContent + "\"Programmierung: Go\": 201104c\n",
+		},
+		{
+			name:  "Id already in topic",
+			topic: "Thermodynamik",
+			ids:   []string{"180112a"},
+			err:   "index: id 180112a already in topic \"Thermodynamik\"",
+		},
+		{
+			name:  "Not an id",
+			topic: "Leben",
+			ids:   []string{"170a"},
+			err:   "index: not an id \"170a\"",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := AddToIndex(editIndexContent, tc.topic, tc.ids...)
+			assertEdit(t, got, err, tc.want, tc.err)
+		})
+	}
+}
+
+func TestEditIndex(t *testing.T) {
+	// The ids in the first line don't belong to a topic.
+	content := "170713a\nLeben: 170713a\nEntropie: 190119e"
+
+	tcs := []struct {
+		name  string
+		lines []string
+		err   bool
+	}{
+		{
+			name:  "An error that only moves is not new",
+			lines: []string{"Wärme: 201104c", "170713a", "Leben: 170713a", "Entropie: 190119e"},
+		},
+		{
+			name:  "An edit fixing one error and adding another",
+			lines: []string{"Leben: 170713a", "Entropie: 190119e", "Entropie: 201104c"},
+			err:   true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := editIndex(content, func(index zet.Index, lines []string) ([]string, error) {
+				return tc.lines, nil
+			})
+			if (err != nil) != tc.err {
+				t.Errorf("got error %v, want an error: %v", err, tc.err)
+			}
+		})
+	}
+}
+
+func TestRemoveFromIndex(t *testing.T) {
+	tcs := []struct {
+		name  string
+		topic string
+		ids   []string
+		want  string
+		err   string
+	}{
+		{
+			name:  "Remove from a continuation line, which gets removed",
+			topic: "Thermodynamik",
+			ids:   []string{"180112a"},
+			want:  "# Topics of my life\nLeben:170713a\nThermodynamik: 170213d,\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see Thermodynamik\nWärme: see Thermodynamik\n",
+		},
+		{
+			name:  "A topic referring to other topics stays",
+			topic: "Entropie",
+			ids:   []string{"190119e"},
+			want:  "# Topics of my life\nLeben:170713a\nThermodynamik: 170213d,\n\t180112a\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: see Thermodynamik\nWärme: see Thermodynamik\n",
+		},
+		{
+			name:  "A topic without ids gets removed",
+			topic: "Leben",
+			ids:   []string{"170713a"},
+			want:  "# Topics of my life\nThermodynamik: 170213d,\n\t180112a\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see Thermodynamik\nWärme: see Thermodynamik\n",
+		},
+		{
+			name:  "Id not in topic",
+			topic: "Leben",
+			ids:   []string{"180112a"},
+			err:   "index: id 180112a not in topic \"Leben\"",
+		},
+		{
+			name:  "Unknown topic",
+			topic: "Arbeit",
+			ids:   []string{"180112a"},
+			err:   "index: topic \"Arbeit\" not found",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RemoveFromIndex(editIndexContent, tc.topic, tc.ids...)
+			assertEdit(t, got, err, tc.want, tc.err)
+		})
+	}
+}
+
+func TestRenameTopic(t *testing.T) {
+	tcs := []struct {
+		name     string
+		oldTopic string
+		newTopic string
+		want     string
+		err      string
+	}{
+		{
+			name:     "Rename a topic and all references to it",
+			oldTopic: "Thermodynamik",
+			newTopic: "Physik: Thermodynamik",
+			want:     "# Topics of my life\nLeben:170713a\n\"Physik: Thermodynamik\": 170213d,\n\t180112a\n\t# Heat is a synonym\n\tsee Entropie\n\nEntropie: 190119e; see \"Physik: Thermodynamik\"\nWärme: see \"Physik: Thermodynamik\"\n",
+		},
+		{
+			name:     "Topic already exists",
+			oldTopic: "Leben",
+			newTopic: "Entropie",
+			err:      "index: topic \"Entropie\" already exists",
+		},
+		{
+			name:     "Unknown topic",
+			oldTopic: "Arbeit",
+			newTopic: "Beruf",
+			err:      "index: topic \"Arbeit\" not found",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RenameTopic(editIndexContent, tc.oldTopic, tc.newTopic)
+			assertEdit(t, got, err, tc.want, tc.err)
+		})
+	}
+}
+
+func assertEdit(t *testing.T, got string, err error, want, wantErr string) {
+	t.Helper()
+	if wantErr != "" {
+		if err == nil || err.Error() != wantErr {
+			t.Errorf("expected error %q, got %v", wantErr, err)
+		}
+		return
+	}
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -0,0 +1,79 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	pageRegex      = regexp.MustCompile(`^(?i:(?:p\.|pp\.|s\.|page|seite)\s*)?(\d+)(?:f|ff)?\.?$`)
+	pageRangeRegex = regexp.MustCompile(`^(?i:(?:p\.|pp\.|s\.|pages|seiten)\s*)?(\d+)\s*(?:-|--|–)\s*(\d+)$`)
+	chapterRegex   = regexp.MustCompile(`^(?i:ch\.|chap\.|chapter|kap\.|kapitel)\s*(\d+)$`)
+	sectionRegex   = regexp.MustCompile(`^(?i:(?:sec\.|section|abschnitt|§)\s*)?(\d+(?:\.\d+)+|\d+)$`)
+	timestampRegex = regexp.MustCompile(`^(?:(\d{1,2}):)?(\d{1,2}):(\d{2})$`)
+	durationRegex  = regexp.MustCompile(`^(?:(\d+)h)?(?:(\d+)m)?(?:(\d+)s)?$`)
+)
+
+// Location parses the location within a literature reference, e.g. the "243-245" in "welter2011 243-245".
+// Supported are
+//   - pages, e.g. "87", "p. 87", "S. 87" or "14f",
+//   - page ranges, e.g. "243-245", "pp. 243-245" or the abbreviated form "243-45",
+//   - chapters, e.g. "ch. 3" or "Kap. 3",
+//   - sections, e.g. "sec. 2.3", "§ 4" or "2.3" and
+//   - timestamps of e.g. movies, e.g. "01:23:45", "23:45" or "1h23m45s".
+//
+// A location that can not be parsed is returned with the kind zet.UnknownLocation.
+func Location(s string) zet.Location {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return zet.Location{}
+	}
+	l := zet.Location{Raw: s, Kind: zet.UnknownLocation}
+
+	if m := pageRegex.FindStringSubmatch(s); m != nil {
+		l.Kind, l.Values = zet.PageLocation, []int{atoi(m[1])}
+		return l
+	}
+	if m := pageRangeRegex.FindStringSubmatch(s); m != nil {
+		from, to := m[1], m[2]
+		// An abbreviated range like "243-45" means "243-245".
+		if len(to) < len(from) {
+			to = from[:len(from)-len(to)] + to
+		}
+		if atoi(to) > atoi(from) {
+			l.Kind, l.Values = zet.PageRangeLocation, []int{atoi(from), atoi(to)}
+		}
+		return l
+	}
+	if m := chapterRegex.FindStringSubmatch(s); m != nil {
+		l.Kind, l.Values = zet.ChapterLocation, []int{atoi(m[1])}
+		return l
+	}
+	if m := sectionRegex.FindStringSubmatch(s); m != nil {
+		l.Kind = zet.SectionLocation
+		for _, n := range strings.Split(m[1], ".") {
+			l.Values = append(l.Values, atoi(n))
+		}
+		return l
+	}
+	if m := timestampRegex.FindStringSubmatch(s); m != nil {
+		minutes, seconds := atoi(m[2]), atoi(m[3])
+		if minutes < 60 && seconds < 60 {
+			l.Kind, l.Values = zet.TimestampLocation, []int{atoi(m[1])*3600 + minutes*60 + seconds}
+		}
+		return l
+	}
+	if m := durationRegex.FindStringSubmatch(s); m != nil {
+		l.Kind, l.Values = zet.TimestampLocation, []int{atoi(m[1])*3600 + atoi(m[2])*60 + atoi(m[3])}
+		return l
+	}
+	return l
+}
+
+// atoi converts a string of digits into a number. An empty string results in 0.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
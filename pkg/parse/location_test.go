@@ -0,0 +1,54 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestLocation(t *testing.T) {
+	var tcs = []struct {
+		in       string
+		location zet.Location
+	}{
+		// No location provided.
+		{"", zet.Location{}},
+
+		// Pages
+		{"87", zet.Location{Raw: "87", Kind: zet.PageLocation, Values: []int{87}}},
+		{"14f", zet.Location{Raw: "14f", Kind: zet.PageLocation, Values: []int{14}}},
+		{"p. 87", zet.Location{Raw: "p. 87", Kind: zet.PageLocation, Values: []int{87}}},
+		{"S. 87", zet.Location{Raw: "S. 87", Kind: zet.PageLocation, Values: []int{87}}},
+
+		// Page ranges, also in the abbreviated form.
+		{"243-245", zet.Location{Raw: "243-245", Kind: zet.PageRangeLocation, Values: []int{243, 245}}},
+		{"pp. 243--245", zet.Location{Raw: "pp. 243--245", Kind: zet.PageRangeLocation, Values: []int{243, 245}}},
+		{"243-45", zet.Location{Raw: "243-45", Kind: zet.PageRangeLocation, Values: []int{243, 245}}},
+
+		// A range must not end before it starts.
+		{"245-243", zet.Location{Raw: "245-243", Kind: zet.UnknownLocation}},
+
+		// Chapters and sections
+		{"ch. 3", zet.Location{Raw: "ch. 3", Kind: zet.ChapterLocation, Values: []int{3}}},
+		{"Kap. 3", zet.Location{Raw: "Kap. 3", Kind: zet.ChapterLocation, Values: []int{3}}},
+		{"sec. 2.3", zet.Location{Raw: "sec. 2.3", Kind: zet.SectionLocation, Values: []int{2, 3}}},
+		{"§ 4", zet.Location{Raw: "§ 4", Kind: zet.SectionLocation, Values: []int{4}}},
+		{"2.3.1", zet.Location{Raw: "2.3.1", Kind: zet.SectionLocation, Values: []int{2, 3, 1}}},
+
+		// Timestamps, e.g. of a movie, in seconds.
+		{"01:23:45", zet.Location{Raw: "01:23:45", Kind: zet.TimestampLocation, Values: []int{5025}}},
+		{"23:45", zet.Location{Raw: "23:45", Kind: zet.TimestampLocation, Values: []int{1425}}},
+		{"1h23m45s", zet.Location{Raw: "1h23m45s", Kind: zet.TimestampLocation, Values: []int{5025}}},
+		{"01:75:45", zet.Location{Raw: "01:75:45", Kind: zet.UnknownLocation}},
+
+		// Everything else can not be parsed.
+		{"somewhere", zet.Location{Raw: "somewhere", Kind: zet.UnknownLocation}},
+	}
+
+	for _, tc := range tcs {
+		got := Location(tc.in)
+		if diff := cmp.Diff(tc.location, got); diff != "" {
+			t.Errorf("%q: %v", tc.in, diff)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Filename parses a filename into a Zettel.
@@ -39,52 +41,76 @@ func Filename(filename string) (zet.Zettel, error) {
 	}, incon
 }
 
-func toFilename(z zet.Zettel) (string, error) {
-	var fn string
-	const fileExt = ".txt"
-
+// Format returns the canonical filename of a zettel in the form
+//
+//	ID - Keywords - Contexts, References - Predecessor.ext
+//
+// where lists are separated by ', ', keywords start with a capital letter and the references come after
+// the contexts. The file extension is taken from the name of the zettel, it is '.txt' for a zettel without
+// a name. Empty keywords and contexts are left out. A keyword, context or reference containing a separator
+// ' - ' or ',' returns an error, since the filename would be parsed differently.
+// It is the inverse of Filename: Filename(Format(z)) returns z with its new name.
+func Format(z zet.Zettel) (string, error) {
 	if z.Id == "" {
 		return "", errors.New("id is missing, but needed for creation of filename")
 	}
-	fn += z.Id
+	fn := z.Id
 
-	if len(z.Keywords) == 0 {
-		return "", errors.New("at least one keyword is needed for creation of filename")
+	var keywords []string
+	for _, k := range nonEmpty(z.Keywords) {
+		keywords = append(keywords, capitalize(k))
 	}
-	fn += " - " + strings.Join(z.Keywords, ", ")
-
-	if len(z.Context) > 0 || len(z.References) > 0 {
-		fn += " - "
+	var contexts []string
+	contexts = append(contexts, nonEmpty(z.Context)...)
+	for _, r := range z.References {
+		ref := r.Bibkey
+		if r.Location.Raw != "" {
+			ref += " " + r.Location.Raw
+		}
+		contexts = append(contexts, ref)
 	}
 
-	if len(z.Context) > 0 {
-		fn += strings.Join(z.Context, ", ")
+	if len(keywords) == 0 && len(contexts) > 0 {
+		return "", errors.New("at least one keyword is needed for creation of filename")
 	}
-
-	if len(z.References) > 0 {
-		for i, l := range z.References {
-			if i == 0 && len(z.Context) == 0 {
-				fn += l.Bibkey
-				if l.Location != "" {
-					fn += " " + l.Location
-				}
-			} else {
-				fn += ", " + l.Bibkey
-				if l.Location != "" {
-					fn += " " + l.Location
-				}
+	for _, v := range append(append([]string{}, keywords...), contexts...) {
+		for _, sep := range []string{" - ", ","} {
+			if strings.Contains(v, sep) {
+				return "", fmt.Errorf("%q contains the separator %q, which is not allowed in a keyword, context or reference", v, sep)
 			}
 		}
 	}
+	if len(keywords) > 0 {
+		fn += " - " + strings.Join(keywords, ", ")
+	}
+	if len(contexts) > 0 {
+		fn += " - " + strings.Join(contexts, ", ")
+	}
+	if z.Predecessor != "" {
+		fn += " - " + z.Predecessor
+	}
 
-	if len(z.Predecessor) > 0 {
-		fn += " - "
-		fn += z.Predecessor
+	ext := ".txt"
+	if i := strings.LastIndex(z.Name, "."); i > 0 {
+		ext = z.Name[i:]
 	}
+	return fn + ext, nil
+}
 
-	fn += fileExt
+// capitalize returns the keyword with its first letter in upper case, e.g. "Lego bauen" for "lego bauen".
+func capitalize(keyword string) string {
+	r, size := utf8.DecodeRuneInString(keyword)
+	return string(unicode.ToUpper(r)) + keyword[size:]
+}
 
-	return fn, nil
+func nonEmpty(s []string) []string {
+	var result []string
+	for _, e := range s {
+		if e = strings.TrimSpace(e); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
 }
 
 func parseId(filename string) string {
@@ -109,6 +135,13 @@ func parseKeywords(filename string) []string {
 	end := strings.Index(filename[start+sepLen:], " - ")
 	if end == -1 {
 		end = strings.LastIndex(filename, ".")
+		if end < start+sepLen {
+			end = len(filename)
+		}
+		// A filename of an id and a predecessor has no keywords, e.g. "170224a - 170223b.txt".
+		if IsId(filename[start+sepLen : end]) {
+			return nil
+		}
 		keywords = strings.Split(filename[start+sepLen:end], ",")
 	} else {
 		keywords = strings.Split(filename[start+sepLen:end+start+sepLen], ",")
@@ -138,7 +171,7 @@ func parseContextFromFilename(fn string) (context, error) {
 		if countAllIds(parts[1]) > 1 {
 			return context{}, fmt.Errorf("parse filename: more than one predecessor for file %q", fn)
 		}
-		if isId(parts[1]) {
+		if IsId(parts[1]) {
 			// The filename consists of an id and a predecessor id.
 			return context{
 				Predecessor: parts[1],
@@ -158,7 +191,7 @@ func parseContextFromFilename(fn string) (context, error) {
 		if countAllIds(parts[2]) > 1 {
 			return context{}, fmt.Errorf("parse filename: more than one predecessor for file %q", fn)
 		}
-		if isId(parts[2]) {
+		if IsId(parts[2]) {
 			// We don't have context, only id - keywords - predecessor
 			return context{
 				Predecessor: parts[2],
@@ -178,7 +211,7 @@ func parseContextFromFilename(fn string) (context, error) {
 	if len(parts) == 4 {
 		// All parts are filled
 		var p string
-		if isId(parts[3]) {
+		if IsId(parts[3]) {
 			p = parts[3]
 		}
 		c, parseErr := parseContext2(parts[2])
@@ -254,7 +287,7 @@ func parseContext(line string) (context, error) {
 
 	var con context
 	for _, elem := range cleanedLine {
-		if isId(elem) {
+		if IsId(elem) {
 			if con.Predecessor != "" {
 				return context{}, fmt.Errorf("more then one predecessor in line: %v", line)
 			}
@@ -302,7 +335,8 @@ func clean(s []string) []string {
 	return clean
 }
 
-func isId(s string) bool {
+// IsId checks if s is a zettel id, e.g. "170224a" or "210328obj".
+func IsId(s string) bool {
 	r, _ := regexp.Compile("^\\d{6}[a-z]{1,3}$")
 	return r.Match([]byte(s))
 }
@@ -319,7 +353,7 @@ func getRef(spl string) zet.Reference {
 	var s = strings.Split(spl, " ")
 	l.Bibkey = r.FindString(s[0])
 	if len(s) > 1 {
-		l.Location = s[1]
+		l.Location = Location(strings.Join(s[1:], " "))
 	}
 
 	return l
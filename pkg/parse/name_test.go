@@ -53,7 +53,7 @@ func TestParseFilename(t *testing.T) {
 				Id:          "170712a",
 				Keywords:    []string{"Evolution", "Lego bauen", "Perfektion"},
 				Predecessor: "190314a",
-				References:  []zet.Reference{{Bibkey: "nick2016", Location: ""}, {Bibkey: "gutmann2000a", Location: "14f"}},
+				References:  []zet.Reference{{Bibkey: "nick2016"}, {Bibkey: "gutmann2000a", Location: zet.Location{Raw: "14f", Kind: zet.PageLocation, Values: []int{14}}}},
 				Context:     []string{"Gespräch Peter"},
 				Name:        "170712a - Evolution,Lego bauen, Perfektion - Gespräch Peter, nick2016, gutmann2000a 14f - 190314a.png",
 			},
@@ -156,3 +156,84 @@ func TestParseFilename(t *testing.T) {
 		}
 	}
 }
+
+func TestFormat(t *testing.T) {
+	tcs := []struct {
+		filename string
+		want     string
+	}{
+		// A canonical filename stays the same.
+		{"170713a - Evolution, Lego bauen, Perfektion.txt", "170713a - Evolution, Lego bauen, Perfektion.txt"},
+		{"170224a - 170223b.png", "170224a - 170223b.png"},
+		{"170224a.pdf", "170224a.pdf"},
+		{"170712a - Evolution - Gespräch Peter, gutmann2000a 14f - 190314a.png", "170712a - Evolution - Gespräch Peter, gutmann2000a 14f - 190314a.png"},
+
+		// Missing spaces and empty keywords are fixed and references come after the contexts.
+		{"170712a - Evolution,Lego bauen, , Perfektion - nick2016, Gespräch Peter, gutmann2000a 14f - 190314a.png",
+			"170712a - Evolution, Lego bauen, Perfektion - Gespräch Peter, nick2016, gutmann2000a 14f - 190314a.png"},
+
+		// Keywords start with a capital letter.
+		{"170713a - evolution, Lego bauen, Ökologie.txt", "170713a - Evolution, Lego bauen, Ökologie.txt"},
+	}
+
+	for _, tc := range tcs {
+		z, err := Filename(tc.filename)
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", tc.filename, err)
+		}
+		got, err := Format(z)
+		if err != nil {
+			t.Errorf("could not format %q: %v", tc.filename, err)
+		}
+		if got != tc.want {
+			t.Errorf("Got %q, wanted %q", got, tc.want)
+		}
+	}
+}
+
+func TestFormatRoundTrip(t *testing.T) {
+	// Filename(Format(z)) returns z with its new name for a zettel with non-empty, capitalised keywords and
+	// contexts without separators.
+	tcs := []zet.Zettel{
+		{Id: "170224a", Name: "old name.pdf"},
+		{Id: "170224a", Predecessor: "170223b"},
+		{Id: "170713a", Keywords: []string{"Evolution", "Lego bauen", "Ökologie"}},
+		{
+			Id:          "170712a",
+			Keywords:    []string{"Evolution"},
+			Context:     []string{"Gespräch Peter", "Movie Dunkirk"},
+			References:  []zet.Reference{{Bibkey: "nick2016"}, {Bibkey: "gutmann2000a", Location: Location("14f")}},
+			Predecessor: "190314a",
+			Name:        "170712a.png",
+		},
+	}
+
+	for _, z := range tcs {
+		name, err := Format(z)
+		if err != nil {
+			t.Fatalf("could not format %+v: %v", z, err)
+		}
+		got, err := Filename(name)
+		if err != nil {
+			t.Fatalf("could not parse %q: %v", name, err)
+		}
+		z.Name = name
+		if diff := cmp.Diff(z, got); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
+
+func TestFormatSeparator(t *testing.T) {
+	tcs := []zet.Zettel{
+		{Id: "170713a", Keywords: []string{"Lego - bauen"}},
+		{Id: "170713a", Keywords: []string{"Evolution"}, Context: []string{"Peter, Paul"}},
+		{Id: "170713a", Keywords: []string{"Evolution"}, References: []zet.Reference{{Bibkey: "nick2016", Location: Location("12 - 14")}}},
+	}
+
+	for _, z := range tcs {
+		if name, err := Format(z); err == nil {
+			t.Errorf("formatted %+v to %q, want an error", z, name)
+		}
+	}
+}
@@ -1,6 +1,9 @@
 package parse
 
-import "github.com/crelder/zet"
+import (
+	"github.com/crelder/zet"
+	"time"
+)
 
 // Parser is a wrapper for the exported functions in this package.
 // Parser satisfies the zet.Parser interface.
@@ -17,10 +20,62 @@ func (p Parser) Content(filename string, zettel []zet.Zettel) (string, error) {
 func (p Parser) Filename(s string) (zet.Zettel, error) {
 	return Filename(s)
 }
+func (p Parser) Format(z zet.Zettel) (string, error) {
+	return Format(z)
+}
+
+func (p Parser) IsId(s string) bool {
+	return IsId(s)
+}
+
+func (p Parser) Header(content string) (zet.Zettel, time.Time, error) {
+	return Header(content)
+}
+
+func (p Parser) HasHeader(content string) bool {
+	return HasHeader(content)
+}
+
 func (p Parser) Index(content string) (zet.Index, []zet.InconErr) {
 	return Index(content)
 }
 
+func (p Parser) AddToIndex(content, topic string, ids ...string) (string, error) {
+	return AddToIndex(content, topic, ids...)
+}
+
+func (p Parser) RemoveFromIndex(content, topic string, ids ...string) (string, error) {
+	return RemoveFromIndex(content, topic, ids...)
+}
+
+func (p Parser) RenameTopic(content, oldTopic, newTopic string) (string, error) {
+	return RenameTopic(content, oldTopic, newTopic)
+}
+
 func (p Parser) Reference(d string) []string {
 	return Reference(d)
 }
+
+func (p Parser) Bibliography(d string) ([]zet.BibEntry, []zet.InconErr) {
+	return Bibliography(d)
+}
+
+func (p Parser) Config(content string) (zet.Config, []zet.InconErr) {
+	return Config(content)
+}
+
+func (p Parser) ReferenceList(content, format string) ([]zet.BibEntry, error) {
+	return ReferenceList(content, format)
+}
+
+func (p Parser) BibTeX(entries []zet.BibEntry) string {
+	return BibTeX(entries)
+}
+
+func (p Parser) Contexts(content string) ([]zet.ContextEntry, []zet.InconErr) {
+	return Contexts(content)
+}
+
+func (p Parser) Annotations(content, format string) ([]zet.Annotation, error) {
+	return Annotations(content, format)
+}
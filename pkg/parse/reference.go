@@ -1,20 +1,286 @@
 package parse
 
 import (
-	"regexp"
+	"fmt"
+	"github.com/crelder/zet"
+	"strings"
+	"unicode"
 )
 
 // Reference parses the string and returns a slice of bibkeys.
-// It uses a two-step process with regex to filter out the bibkeys.
 func Reference(s string) []string {
-	re1 := regexp.MustCompile(`[@][a-z]+[{][a-z]+\d{4}[a-z]?[,]`)
-	litKeys := re1.FindAll([]byte(s), -1)
+	entries, _ := Bibliography(s)
 
-	re2 := regexp.MustCompile(`[a-z]+\d{4}[a-z]?`)
 	var result []string
-	for _, litKey := range litKeys {
-		result = append(result, string(re2.Find(litKey)))
+	for _, e := range entries {
+		result = append(result, e.Key)
 	}
-
 	return result
 }
+
+// Bibliography parses the content of a BibTeX or BibLaTeX file into its entries.
+// It returns all parsing errors that occurred while parsing the entries. An entry with a parsing error is skipped.
+//
+// Everything outside an entry and entries of type @comment and @preamble are ignored.
+// Macros defined via @string (and the predefined month macros like "jan") are expanded.
+// Field values can be enclosed in braces or quotes, be a number or a macro, and be concatenated via '#'.
+// The names of the types and fields are returned in lower case.
+func Bibliography(s string) ([]zet.BibEntry, []zet.InconErr) {
+	p := bibParser{
+		s:      s,
+		macros: make(map[string]string),
+	}
+	for _, m := range []string{"January", "February", "March", "April", "May", "June", "July",
+		"August", "September", "October", "November", "December"} {
+		p.macros[strings.ToLower(m[:3])] = m
+	}
+
+	var entries []zet.BibEntry
+	var parseErrs []zet.InconErr
+	for p.next() {
+		start := p.pos
+		e, err := p.entry()
+		if err != nil {
+			parseErrs = append(parseErrs, zet.InconErr{Message: err, Rule: zet.ReferencesSyntaxRule, Severity: zet.ErrorSeverity})
+			// Continue with the next entry, even if it starts within the erroneous one.
+			p.pos = start
+			continue
+		}
+		if e.Key != "" {
+			entries = append(entries, e)
+		}
+	}
+	return entries, parseErrs
+}
+
+// bibParser holds the state while parsing the content s of a BibTeX file.
+type bibParser struct {
+	s      string
+	pos    int
+	macros map[string]string
+}
+
+// next moves behind the next '@' starting an entry, that is an '@' followed by the entry type and '{' or '('.
+// Any other '@', e.g. of an email address in a comment, is part of a comment. It returns false, if there is no entry.
+func (p *bibParser) next() bool {
+	for {
+		i := strings.IndexByte(p.s[p.pos:], '@')
+		if i == -1 {
+			p.pos = len(p.s)
+			return false
+		}
+		p.pos += i + 1
+		if p.isEntry() {
+			return true
+		}
+	}
+}
+
+// isEntry checks if an entry type followed by '{' or '(' comes next, without moving on.
+func (p *bibParser) isEntry() bool {
+	start := p.pos
+	defer func() { p.pos = start }()
+	if p.identifier() == "" {
+		return false
+	}
+	p.skipSpace()
+	return p.peek() == '{' || p.peek() == '('
+}
+
+// entry parses an entry directly after its '@', which is followed by the entry type and '{' or '(', see next.
+// Macro definitions (@string) are stored in the parser and an empty entry is returned.
+func (p *bibParser) entry() (zet.BibEntry, error) {
+	start := p.pos
+	typ := strings.ToLower(p.identifier())
+	p.skipSpace()
+	open := p.peek()
+	closing := byte('}')
+	if open == '(' {
+		closing = ')'
+	}
+
+	switch typ {
+	case "comment", "preamble":
+		if _, err := p.braced(open, closing); err != nil {
+			return zet.BibEntry{}, err
+		}
+		return zet.BibEntry{}, nil
+	case "string":
+		p.pos++
+		name, value, err := p.field()
+		if err != nil {
+			return zet.BibEntry{}, err
+		}
+		p.macros[name] = value
+		p.skipSpace()
+		if p.peek() == closing {
+			p.pos++
+		}
+		return zet.BibEntry{}, nil
+	}
+
+	p.pos++
+	p.skipSpace()
+	keyStart := p.pos
+	for p.pos < len(p.s) && p.s[p.pos] != ',' && p.s[p.pos] != closing && p.s[p.pos] != '\n' {
+		p.pos++
+	}
+	key := strings.TrimSpace(p.s[keyStart:p.pos])
+	if key == "" {
+		return zet.BibEntry{}, p.errorf(start, "missing bibkey in @%v", typ)
+	}
+
+	e := zet.BibEntry{
+		Type:   typ,
+		Key:    key,
+		Fields: make(map[string]string),
+	}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.s) {
+			return zet.BibEntry{}, p.errorf(start, "entry %q is not closed", key)
+		}
+		switch p.peek() {
+		case closing:
+			p.pos++
+			return e, nil
+		case ',':
+			p.pos++
+			continue
+		}
+		name, value, err := p.field()
+		if err != nil {
+			return zet.BibEntry{}, err
+		}
+		e.Fields[name] = value
+	}
+}
+
+// field parses a field like 'title = {Algorithms}' and returns its name in lower case and its value.
+func (p *bibParser) field() (string, string, error) {
+	p.skipSpace()
+	start := p.pos
+	name := strings.ToLower(p.identifier())
+	if name == "" {
+		return "", "", p.errorf(start, "could not parse field name")
+	}
+	p.skipSpace()
+	if p.peek() != '=' {
+		return "", "", p.errorf(p.pos, "missing '=' after field %q", name)
+	}
+	p.pos++
+
+	var value string
+	for {
+		p.skipSpace()
+		part, err := p.value()
+		if err != nil {
+			return "", "", err
+		}
+		value += part
+		p.skipSpace()
+		if p.peek() != '#' {
+			break
+		}
+		p.pos++
+	}
+	return name, strings.Join(strings.Fields(value), " "), nil
+}
+
+// value parses a single part of a field value: a braced or quoted string, a number or a macro.
+func (p *bibParser) value() (string, error) {
+	start := p.pos
+	switch c := p.peek(); {
+	case c == '{':
+		return p.braced('{', '}')
+	case c == '"':
+		p.pos++
+		depth := 0
+		for p.pos < len(p.s) {
+			switch p.s[p.pos] {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			case '"':
+				if depth == 0 {
+					v := p.s[start+1 : p.pos]
+					p.pos++
+					return v, nil
+				}
+			}
+			p.pos++
+		}
+		return "", p.errorf(start, "missing closing '\"'")
+	case c >= '0' && c <= '9':
+		for p.pos < len(p.s) && p.s[p.pos] >= '0' && p.s[p.pos] <= '9' {
+			p.pos++
+		}
+		return p.s[start:p.pos], nil
+	default:
+		name := p.identifier()
+		if name == "" {
+			return "", p.errorf(start, "could not parse field value")
+		}
+		v, ok := p.macros[strings.ToLower(name)]
+		if !ok {
+			return "", p.errorf(start, "undefined @string %q", name)
+		}
+		return v, nil
+	}
+}
+
+// braced returns the content between the open and the closing character, which might contain nested braces.
+func (p *bibParser) braced(open, closing byte) (string, error) {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.s) {
+		switch p.s[p.pos] {
+		case open:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.s[start+1 : p.pos-1], nil
+			}
+		}
+		p.pos++
+	}
+	return "", p.errorf(start, "missing closing '%c'", closing)
+}
+
+// identifier returns a name like an entry type, a field name or a macro.
+func (p *bibParser) identifier() string {
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := rune(p.s[p.pos])
+		if !unicode.IsLetter(c) && !unicode.IsDigit(c) && !strings.ContainsRune("_-:.+/", c) {
+			break
+		}
+		p.pos++
+	}
+	return p.s[start:p.pos]
+}
+
+func (p *bibParser) skipSpace() {
+	for p.pos < len(p.s) && unicode.IsSpace(rune(p.s[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *bibParser) peek() byte {
+	if p.pos >= len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+// errorf returns an error containing the line number of the position pos.
+func (p *bibParser) errorf(pos int, format string, a ...interface{}) error {
+	if pos > len(p.s) {
+		pos = len(p.s)
+	}
+	line := strings.Count(p.s[:pos], "\n") + 1
+	return fmt.Errorf("references: line %d: %v", line, fmt.Sprintf(format, a...))
+}
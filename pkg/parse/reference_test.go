@@ -1,6 +1,8 @@
 package parse
 
 import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
 	"reflect"
 	"testing"
 )
@@ -34,3 +36,91 @@ func TestReference(t *testing.T) {
 		})
 	}
 }
+
+func TestBibliography(t *testing.T) {
+	tests := []struct {
+		name        string
+		references  string
+		want        []zet.BibEntry
+		firstErrMsg string
+	}{
+		{"Types and field names are lower case, values can be braced, quoted or numbers",
+			`@Book{kernighan1999,
+	Author = {Kernighan, Brian W. and Pike, Rob},
+	title = "The {P}ractice of
+	         Programming",
+	year = 1999,
+}`,
+			[]zet.BibEntry{{Type: "book", Key: "kernighan1999", Fields: map[string]string{
+				"author": "Kernighan, Brian W. and Pike, Rob",
+				"title":  "The {P}ractice of Programming",
+				"year":   "1999",
+			}}},
+			""},
+
+		{"Bibkeys can have any style",
+			`@article{Pike:1989:Notes-C, title = {Notes on Programming in C}}`,
+			[]zet.BibEntry{{Type: "article", Key: "Pike:1989:Notes-C", Fields: map[string]string{
+				"title": "Notes on Programming in C",
+			}}},
+			""},
+
+		{"Macros are expanded and values concatenated, comments are ignored",
+			`This text is a comment.
+@comment{kernighan1999, this is not an entry}
+@string{aw = "Addison-Wesley"}
+@preamble{"\newcommand{\noop}[1]{}"}
+@book(sedgewick2011,
+	publisher = aw # { Professional},
+	month = jan)`,
+			[]zet.BibEntry{{Type: "book", Key: "sedgewick2011", Fields: map[string]string{
+				"publisher": "Addison-Wesley Professional",
+				"month":     "January",
+			}}},
+			""},
+
+		{"An '@' outside an entry is part of a comment",
+			`% contact me@example.org
+@book{knuth1997, title={TAOCP}}
+Send corrections to @knuth, not to the list.`,
+			[]zet.BibEntry{{Type: "book", Key: "knuth1997", Fields: map[string]string{
+				"title": "TAOCP",
+			}}},
+			""},
+
+		{"An entry following an unclosed entry is parsed",
+			`@book{welter2011, title = {Komplexität
+@book{sedgewick2011, title = {Algorithms}}`,
+			[]zet.BibEntry{{Type: "book", Key: "sedgewick2011", Fields: map[string]string{
+				"title": "Algorithms",
+			}}},
+			"references: line 1: missing closing '}'"},
+
+		{"An erroneous entry is skipped and the error contains the line number",
+			`@book{welter2011,
+	title = {Komplexität},
+	publisher = unknown}
+@book{sedgewick2011, title = {Algorithms}}`,
+			[]zet.BibEntry{{Type: "book", Key: "sedgewick2011", Fields: map[string]string{
+				"title": "Algorithms",
+			}}},
+			"references: line 3: undefined @string \"unknown\""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := Bibliography(tt.references)
+			if diff := cmp.Diff(tt.want, got); diff != "" {
+				t.Errorf(diff)
+			}
+
+			var errMsg string
+			if errs != nil {
+				errMsg = errs[0].Error()
+			}
+			if errMsg != tt.firstErrMsg {
+				t.Errorf("Got %q, wanted %q", errMsg, tt.firstErrMsg)
+			}
+		})
+	}
+}
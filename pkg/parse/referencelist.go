@@ -0,0 +1,302 @@
+package parse
+
+import (
+	"encoding/xml"
+	"fmt"
+	"github.com/crelder/zet"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// risTypes maps RIS reference types to BibTeX entry types. All other types become "misc".
+var risTypes = map[string]string{
+	"JOUR":   "article",
+	"JFULL":  "article",
+	"MGZN":   "article",
+	"NEWS":   "article",
+	"BOOK":   "book",
+	"EBOOK":  "book",
+	"EDBOOK": "book",
+	"CHAP":   "incollection",
+	"ECHAP":  "incollection",
+	"CONF":   "inproceedings",
+	"CPAPER": "inproceedings",
+	"THES":   "phdthesis",
+	"RPRT":   "techreport",
+	"ELEC":   "online",
+	"WEB":    "online",
+	"UNPB":   "unpublished",
+}
+
+// endNoteTypes maps the names of EndNote reference types to BibTeX entry types. All other types become "misc".
+var endNoteTypes = map[string]string{
+	"journal article":        "article",
+	"magazine article":       "article",
+	"newspaper article":      "article",
+	"book":                   "book",
+	"edited book":            "book",
+	"electronic book":        "book",
+	"book section":           "incollection",
+	"conference proceedings": "inproceedings",
+	"conference paper":       "inproceedings",
+	"thesis":                 "phdthesis",
+	"report":                 "techreport",
+	"web page":               "online",
+	"unpublished work":       "unpublished",
+}
+
+var (
+	yearRegex    = regexp.MustCompile(`\d{4}`)
+	pageSepRegex = regexp.MustCompile(`\s*[-–]+\s*`)
+)
+
+// ReferenceList parses a list of literature references exported from a reference manager
+// in the format "ris" or "xml" (EndNote XML) into BibTeX entries.
+// The entries have no Key, since the bibkeys must be generated with regard to your existing references.
+func ReferenceList(content, format string) ([]zet.BibEntry, error) {
+	switch strings.ToLower(format) {
+	case "ris":
+		return ris(content)
+	case "xml":
+		return endNote(content)
+	default:
+		return nil, fmt.Errorf("parse references: format %q not supported, use RIS or EndNote XML", format)
+	}
+}
+
+// ris parses references in the RIS format, where every line consists of a tag and a value, e.g.
+//
+//	TY  - JOUR
+//	AU  - Welter, Thomas
+//	PY  - 2011
+//	ER  -
+func ris(content string) ([]zet.BibEntry, error) {
+	var entries []zet.BibEntry
+	var tags map[string][]string
+	var risType string
+	for n, line := range strings.Split(strings.TrimPrefix(content, "\ufeff"), "\n") {
+		line = strings.TrimRight(line, " \r")
+		if line == "" {
+			continue
+		}
+		if len(line) < 5 || line[2:5] != "  -" {
+			return nil, fmt.Errorf("parse references: line %d: could not parse %q, should be 'XX  - value'", n+1, line)
+		}
+		tag, value := line[:2], strings.TrimSpace(line[5:])
+
+		switch {
+		case tag == "TY":
+			risType, tags = value, make(map[string][]string)
+		case tags == nil:
+			return nil, fmt.Errorf("parse references: line %d: reference must start with 'TY  - '", n+1)
+		case tag == "ER":
+			entries = append(entries, risEntry(risType, tags))
+			tags = nil
+		default:
+			tags[tag] = append(tags[tag], value)
+		}
+	}
+	if tags != nil {
+		return nil, fmt.Errorf("parse references: last reference must end with 'ER  - '")
+	}
+	return entries, nil
+}
+
+func risEntry(risType string, tags map[string][]string) zet.BibEntry {
+	e := newEntry(risTypes[risType])
+
+	get := func(names ...string) string {
+		for _, name := range names {
+			if v := tags[name]; len(v) > 0 {
+				return v[0]
+			}
+		}
+		return ""
+	}
+	all := func(names ...string) []string {
+		var values []string
+		for _, name := range names {
+			values = append(values, tags[name]...)
+		}
+		return values
+	}
+
+	e.set("author", strings.Join(all("AU", "A1"), " and "))
+	e.set("editor", strings.Join(all("A2", "ED"), " and "))
+	e.set("title", get("TI", "T1"))
+	switch e.Type {
+	case "article":
+		e.set("journal", get("JO", "JF", "T2", "JA"))
+	case "incollection", "inproceedings":
+		e.set("booktitle", get("T2", "BT"))
+	}
+	e.set("year", yearRegex.FindString(get("PY", "Y1", "DA")))
+	e.set(publisherField(e.Type), get("PB"))
+	e.set("address", get("CY"))
+	e.set("volume", get("VL"))
+	e.set("number", get("IS"))
+	e.set("pages", joinPages(get("SP"), get("EP")))
+	e.set("doi", get("DO"))
+	e.set("url", get("UR"))
+	e.set("isbn", get("SN"))
+	e.set("abstract", get("AB"))
+	e.set("keywords", strings.Join(all("KW"), ", "))
+	e.set("note", get("N1"))
+	return zet.BibEntry(e)
+}
+
+// endNoteText is a text in EndNote XML, which is either given directly or in style elements.
+type endNoteText struct {
+	Text   string   `xml:",chardata"`
+	Styles []string `xml:"style"`
+}
+
+func (t endNoteText) String() string {
+	return strings.TrimSpace(t.Text + strings.Join(t.Styles, ""))
+}
+
+type endNoteRecord struct {
+	RefType struct {
+		Name string `xml:"name,attr"`
+	} `xml:"ref-type"`
+	Authors        []endNoteText `xml:"contributors>authors>author"`
+	Editors        []endNoteText `xml:"contributors>secondary-authors>author"`
+	Title          endNoteText   `xml:"titles>title"`
+	SecondaryTitle endNoteText   `xml:"titles>secondary-title"`
+	Periodical     endNoteText   `xml:"periodical>full-title"`
+	Year           endNoteText   `xml:"dates>year"`
+	Publisher      endNoteText   `xml:"publisher"`
+	PubLocation    endNoteText   `xml:"pub-location"`
+	Volume         endNoteText   `xml:"volume"`
+	Number         endNoteText   `xml:"number"`
+	Pages          endNoteText   `xml:"pages"`
+	Doi            endNoteText   `xml:"electronic-resource-num"`
+	Urls           []endNoteText `xml:"urls>related-urls>url"`
+	Isbn           endNoteText   `xml:"isbn"`
+	Abstract       endNoteText   `xml:"abstract"`
+	Keywords       []endNoteText `xml:"keywords>keyword"`
+	Notes          endNoteText   `xml:"notes"`
+}
+
+// endNote parses references in the EndNote XML format.
+func endNote(content string) ([]zet.BibEntry, error) {
+	var doc struct {
+		Records []endNoteRecord `xml:"records>record"`
+	}
+	if err := xml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("parse references: %v", err)
+	}
+
+	var entries []zet.BibEntry
+	for _, r := range doc.Records {
+		e := newEntry(endNoteTypes[strings.ToLower(r.RefType.Name)])
+		e.set("author", joinTexts(r.Authors, " and "))
+		e.set("editor", joinTexts(r.Editors, " and "))
+		e.set("title", r.Title.String())
+		switch e.Type {
+		case "article":
+			journal := r.SecondaryTitle.String()
+			if journal == "" {
+				journal = r.Periodical.String()
+			}
+			e.set("journal", journal)
+		case "incollection", "inproceedings":
+			e.set("booktitle", r.SecondaryTitle.String())
+		}
+		e.set("year", yearRegex.FindString(r.Year.String()))
+		e.set(publisherField(e.Type), r.Publisher.String())
+		e.set("address", r.PubLocation.String())
+		e.set("volume", r.Volume.String())
+		e.set("number", r.Number.String())
+		e.set("pages", pageSepRegex.ReplaceAllString(r.Pages.String(), "--"))
+		e.set("doi", r.Doi.String())
+		if len(r.Urls) > 0 {
+			e.set("url", r.Urls[0].String())
+		}
+		e.set("isbn", r.Isbn.String())
+		e.set("abstract", r.Abstract.String())
+		e.set("keywords", joinTexts(r.Keywords, ", "))
+		e.set("note", r.Notes.String())
+		entries = append(entries, zet.BibEntry(e))
+	}
+	return entries, nil
+}
+
+// entry is a zet.BibEntry that only holds fields with a value.
+type entry zet.BibEntry
+
+func newEntry(entryType string) entry {
+	if entryType == "" {
+		entryType = "misc"
+	}
+	return entry{Type: entryType, Fields: make(map[string]string)}
+}
+
+func (e entry) set(name, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		e.Fields[name] = value
+	}
+}
+
+// publisherField returns the BibTeX field of the publisher of an entry type.
+// The publisher of a thesis is the university, which BibTeX holds in the field 'school'.
+func publisherField(entryType string) string {
+	if entryType == "phdthesis" {
+		return "school"
+	}
+	return "publisher"
+}
+
+func joinTexts(texts []endNoteText, sep string) string {
+	var s []string
+	for _, t := range texts {
+		if v := t.String(); v != "" {
+			s = append(s, v)
+		}
+	}
+	return strings.Join(s, sep)
+}
+
+func joinPages(start, end string) string {
+	if start == "" || end == "" {
+		return start
+	}
+	return start + "--" + end
+}
+
+// BibTeX formats the entries in the BibTeX format with their fields sorted by name, e.g.
+//
+//	@book{welter2011,
+//		author = {Welter, Thomas},
+//		title = {Komplexität},
+//		year = {2011}}
+func BibTeX(entries []zet.BibEntry) string {
+	var lines []string
+	for n, e := range entries {
+		if n > 0 {
+			lines = append(lines, "")
+		}
+		var names []string
+		for name := range e.Fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if len(names) == 0 {
+			lines = append(lines, "@"+e.Type+"{"+e.Key+"}")
+			continue
+		}
+
+		lines = append(lines, "@"+e.Type+"{"+e.Key+",")
+		for i, name := range names {
+			line := "\t" + name + " = {" + e.Fields[name] + "}"
+			if i < len(names)-1 {
+				line += ","
+			} else {
+				line += "}"
+			}
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
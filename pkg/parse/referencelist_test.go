@@ -0,0 +1,99 @@
+package parse
+
+import (
+	"github.com/crelder/zet"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestReferenceList(t *testing.T) {
+	var tcs = []struct {
+		name    string
+		content string
+		format  string
+		entries []zet.BibEntry
+		err     string
+	}{
+		{
+			name:    "RIS with unknown type",
+			content: "TY  - GEN\r\nAU  - Rob Pike\r\nTI  - Notes on Programming in C\r\nPY  - 1989\r\nER  - \r\n",
+			format:  "ris",
+			entries: []zet.BibEntry{{Type: "misc", Fields: map[string]string{"author": "Rob Pike", "title": "Notes on Programming in C", "year": "1989"}}},
+		},
+		{
+			name:    "RIS thesis with the university as publisher",
+			content: "TY  - THES\nAU  - Welter, Rudolf\nTI  - Komplexität\nPY  - 2011\nPB  - Universität Bielefeld\nER  - \n",
+			format:  "ris",
+			entries: []zet.BibEntry{{Type: "phdthesis", Fields: map[string]string{"author": "Welter, Rudolf", "title": "Komplexität", "year": "2011", "school": "Universität Bielefeld"}}},
+		},
+		{
+			name:    "RIS without end of reference",
+			content: "TY  - BOOK\nTI  - Algorithms",
+			format:  "ris",
+			err:     "parse references: last reference must end with 'ER  - '",
+		},
+		{
+			name:    "RIS without type",
+			content: "TI  - Algorithms\nER  - ",
+			format:  "ris",
+			err:     "parse references: line 1: reference must start with 'TY  - '",
+		},
+		{
+			name:    "RIS with invalid line",
+			content: "TY  - BOOK\nAlgorithms\nER  - ",
+			format:  "ris",
+			err:     "parse references: line 2: could not parse \"Algorithms\", should be 'XX  - value'",
+		},
+		{
+			name: "EndNote XML with text in and outside of style elements",
+			content: `<xml><records><record><ref-type name="Journal Article">17</ref-type>
+				<contributors><authors><author>Pike, Rob</author></authors></contributors>
+				<titles><title><style>Notes on </style><style>Programming in C</style></title></titles>
+				<periodical><full-title>Unix Review</full-title></periodical>
+				<pages>20 – 23</pages><dates><year>1989</year></dates></record></records></xml>`,
+			format:  "xml",
+			entries: []zet.BibEntry{{Type: "article", Fields: map[string]string{"author": "Pike, Rob", "title": "Notes on Programming in C", "journal": "Unix Review", "pages": "20--23", "year": "1989"}}},
+		},
+		{
+			name:   "Unsupported format",
+			format: "enw",
+			err:    "parse references: format \"enw\" not supported, use RIS or EndNote XML",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := ReferenceList(tc.content, tc.format)
+			if tc.err != "" {
+				if err == nil || err.Error() != tc.err {
+					t.Errorf("expected error %q, got %v", tc.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.entries, entries); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func TestBibTeX(t *testing.T) {
+	entries := []zet.BibEntry{
+		{Type: "book", Key: "kernighan1999", Fields: map[string]string{"title": "The practice of programming", "author": "Kernighan, Brian W. and Pike, Rob"}},
+		{Type: "misc", Key: "empty2020"},
+	}
+	want := "@book{kernighan1999,\n\tauthor = {Kernighan, Brian W. and Pike, Rob},\n\ttitle = {The practice of programming}}\n\n@misc{empty2020}"
+
+	if diff := cmp.Diff(want, BibTeX(entries)); diff != "" {
+		t.Errorf(diff)
+	}
+
+	// The formatted entries can be parsed again.
+	parsed, parseErrs := Bibliography(BibTeX(entries))
+	if len(parseErrs) != 0 || len(parsed) != 2 {
+		t.Errorf("could not parse formatted entries: %v", parseErrs)
+	}
+}
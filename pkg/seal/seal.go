@@ -0,0 +1,36 @@
+package seal
+
+import (
+	"fmt"
+)
+
+// Sealer records the checksums of your zettel files, so that changes to them get noticed.
+// Sealer satisfies the zet.Sealer interface.
+type Sealer struct {
+	Store Store
+}
+
+// Store computes the checksums of your zettel files and saves them (map[filename]checksum).
+type Store interface {
+	GetChecksums() (map[string]string, error)
+	SaveSeal(seal map[string]string) error
+}
+
+func New(s Store) Sealer {
+	return Sealer{
+		Store: s,
+	}
+}
+
+// Seal records the SHA-256 checksum of every file in the folder 'zettel' and returns the number of files.
+// A former seal is replaced, so sealing again accepts all changes made since.
+func (s Sealer) Seal() (int, error) {
+	checksums, err := s.Store.GetChecksums()
+	if err != nil {
+		return 0, fmt.Errorf("seal: %w", err)
+	}
+	if err := s.Store.SaveSeal(checksums); err != nil {
+		return 0, fmt.Errorf("seal: %w", err)
+	}
+	return len(checksums), nil
+}
@@ -0,0 +1,96 @@
+package seal
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"github.com/crelder/zet/pkg/parse"
+	"github.com/crelder/zet/pkg/transport/fs"
+	"github.com/crelder/zet/pkg/validate"
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSeal(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.Mkdir(dir+"/zettel", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"170224a - Go.txt":          "Go",
+		"170225b - Testing.txt":     "Testing",
+		"170226c - Deleted.txt":     "Deleted",
+		"170227d - Renamed,Go .txt": "Renamed",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(dir+"/zettel/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, f := range []string{"/index.txt", "/references.bib"} {
+		if err := os.WriteFile(dir+f, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parser := parse.New()
+	repo := fs.New(dir, parser)
+	sealer := New(repo)
+	validator := validate.New(repo, repo, parser, repo)
+
+	// Act
+	n, err := sealer.Seal()
+	if err != nil {
+		t.Fatalf("Could not seal: %v", err)
+	}
+	if n != 4 {
+		t.Errorf("sealed %d files, want 4", n)
+	}
+	seal, err := os.ReadFile(dir + "/zettel.sha256")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The seal can be checked with 'sha256sum -c zettel.sha256'.
+	line := fmt.Sprintf("%x  zettel/170224a - Go.txt\n", sha256.Sum256([]byte("Go")))
+	if !strings.HasPrefix(string(seal), line) {
+		t.Errorf("seal %q doesn't start with %q", seal, line)
+	}
+
+	// Importing and renaming keep the seal up to date, all other changes are reported.
+	if _, err := repo.Save(map[string][]byte{"170228e - Imported.txt": []byte("Imported")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.RenameZettel("170227d - Renamed,Go .txt", "170227d - Renamed, Go.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/zettel/170225b - Testing.txt", []byte("Edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(dir + "/zettel/170226c - Deleted.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/zettel/170301f - Copied.txt", []byte("Copied"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Assert
+	incons, err := validator.Val()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, i := range incons {
+		if strings.HasPrefix(i.Error(), "seal:") {
+			got = append(got, string(i.Severity)+" "+i.Rule+" "+i.Id)
+		}
+	}
+	want := []string{
+		"error changed-zettel 170225b",
+		"error missing-zettel 170226c",
+		"warning unsealed-zettel 170301f",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
@@ -1,11 +1,13 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/crelder/zet"
 	"github.com/crelder/zet/pkg/export"
 	"github.com/crelder/zet/pkg/index"
 	"os"
+	"strings"
 )
 
 const version = "0.3.0"
@@ -16,15 +18,21 @@ type App struct {
 	exporter  export.Exporter
 	validator zet.Validator
 	initiator zet.Initiator
+	formatter zet.Formatter
+	sealer    zet.Sealer
+	backuper  zet.Backuper
 }
 
-func NewApp(importer zet.Importer, exporter export.Exporter, indexer index.Indexer, validator zet.Validator, initiator zet.Initiator) App {
+func NewApp(importer zet.Importer, exporter export.Exporter, indexer index.Indexer, validator zet.Validator, initiator zet.Initiator, formatter zet.Formatter, sealer zet.Sealer, backuper zet.Backuper) App {
 	return App{
 		importer:  importer,
 		indexer:   indexer,
 		exporter:  exporter,
 		validator: validator,
 		initiator: initiator,
+		formatter: formatter,
+		sealer:    sealer,
+		backuper:  backuper,
 	}
 }
 
@@ -68,6 +76,30 @@ func (cli App) Start() error {
 		return nil
 	}
 
+	// Backups can be verified and restored from anywhere.
+	if subcmd == "backup" && len(os.Args) > 2 && os.Args[2] == "verify" {
+		if len(os.Args) != 4 {
+			return fmt.Errorf("command 'zet backup verify' needs the archive to verify")
+		}
+		n, err := cli.backuper.Verify(os.Args[3])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Verified %d files in %v", n, os.Args[3])
+		return nil
+	}
+	if subcmd == "restore" {
+		if len(os.Args) != 4 {
+			return fmt.Errorf("command 'zet restore' needs the archive and an empty folder to restore into")
+		}
+		n, err := cli.backuper.Restore(os.Args[2], os.Args[3])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Restored and verified %d files into %v", n, os.Args[3])
+		return nil
+	}
+
 	// From here on, the user must be in his zettelkasten to execute the following commands.
 	if !isCalledFromZetDir() {
 		fmt.Println("It seems you are not in your zettelkasten directory.")
@@ -81,7 +113,21 @@ func (cli App) Start() error {
 			return fmt.Errorf("no path provided. Please provide a path to the folder, where the textfiles lie, which you want to import")
 		}
 
-		n, err2 := cli.importer.Import(os.Args[2])
+		var n int
+		var err2 error
+		if os.Args[2] == "--annotations" {
+			if len(os.Args) < 4 {
+				return fmt.Errorf("no file provided. Please provide a CSV or JSON file with the annotations, which you want to import")
+			}
+			n, err2 = cli.importer.ImportAnnotations(os.Args[3])
+		} else if os.Args[2] == "--scans" {
+			if len(os.Args) < 4 {
+				return fmt.Errorf("no path provided. Please provide a path to the folder, where the scans and their sidecar textfiles or manifest.csv lie")
+			}
+			n, err2 = cli.importer.ImportScans(os.Args[3])
+		} else {
+			n, err2 = cli.importer.Import(os.Args[2])
+		}
 		if err2 != nil {
 			if n == 0 {
 				return fmt.Errorf("error importing: %v", err2)
@@ -93,6 +139,21 @@ func (cli App) Start() error {
 		fmt.Printf("Imported %d zettel into your zettel folder", n)
 		return nil
 	case "export":
+		if len(os.Args) > 2 && os.Args[2] == "refs" {
+			if len(os.Args) > 4 {
+				return fmt.Errorf("command 'zet export refs' takes at most one topic or id")
+			}
+			var selection string
+			if len(os.Args) == 4 {
+				selection = os.Args[3]
+			}
+			err := cli.exporter.ExportReferences(selection)
+			if err != nil {
+				return fmt.Errorf("Could not export references: %v\n", err)
+			}
+			fmt.Printf("Exported the cited references to 'EXPORT/cited.bib' and 'EXPORT/cited.json'")
+			return nil
+		}
 		if len(os.Args) > 2 {
 			return fmt.Errorf("command 'zet export' does not need any parameters")
 		}
@@ -102,74 +163,298 @@ func (cli App) Start() error {
 		}
 		return nil
 	case "index":
-		if len(os.Args) > 2 {
-			return fmt.Errorf("command 'zet index' does not need any parameters")
+		if len(os.Args) > 2 && (os.Args[2] == "add" || os.Args[2] == "remove") {
+			if len(os.Args) < 5 {
+				return fmt.Errorf("command 'zet index %v' needs a topic and at least one id", os.Args[2])
+			}
+			topic, ids := os.Args[3], os.Args[4:]
+			if os.Args[2] == "add" {
+				if err := cli.indexer.AddToIndex(topic, ids); err != nil {
+					return fmt.Errorf("Could not add to index: %v\n", err)
+				}
+				fmt.Printf("Added %v to topic %q in your index.txt", strings.Join(ids, ", "), topic)
+				return nil
+			}
+			if err := cli.indexer.RemoveFromIndex(topic, ids); err != nil {
+				return fmt.Errorf("Could not remove from index: %v\n", err)
+			}
+			fmt.Printf("Removed %v from topic %q in your index.txt", strings.Join(ids, ", "), topic)
+			return nil
+		}
+		if len(os.Args) > 2 && os.Args[2] == "rename" {
+			if len(os.Args) != 5 {
+				return fmt.Errorf("command 'zet index rename' needs the old and the new topic")
+			}
+			if err := cli.indexer.RenameTopic(os.Args[3], os.Args[4]); err != nil {
+				return fmt.Errorf("Could not rename topic: %v\n", err)
+			}
+			fmt.Printf("Renamed topic %q to %q in your index.txt", os.Args[3], os.Args[4])
+			return nil
+		}
+		dryRun := len(os.Args) == 3 && os.Args[2] == "--dry-run"
+		if len(os.Args) > 2 && !dryRun {
+			return fmt.Errorf("command 'zet index' only takes the parameter '--dry-run'")
 		}
-		err := cli.indexer.Create()
+		changes, err := cli.indexer.Create(dryRun)
 		if err != nil {
 			return fmt.Errorf("Could not create index: %v\n", err)
 		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		switch {
+		case dryRun:
+			fmt.Printf("%d changes to the folder 'INDEX' would be made", len(changes))
+		case len(changes) == 0:
+			fmt.Printf("The folder 'INDEX' is up to date")
+		default:
+			fmt.Printf("Made %d changes to the folder 'INDEX'", len(changes))
+		}
 		return nil
-	case "validate":
+	case "refs":
+		if len(os.Args) > 2 && os.Args[2] == "import" {
+			if len(os.Args) != 4 {
+				return fmt.Errorf("no file provided. Please provide a RIS or EndNote XML file with the references, which you want to import")
+			}
+			n, err := cli.importer.ImportReferences(os.Args[3])
+			if err != nil {
+				return fmt.Errorf("error importing references: %v", err)
+			}
+			fmt.Printf("Added %d references to your references.bib", n)
+			return nil
+		}
+		if len(os.Args) > 2 {
+			return fmt.Errorf("command 'zet refs' does not need any parameters")
+		}
+		err := cli.indexer.CreateReferences()
+		if err != nil {
+			return fmt.Errorf("Could not create references: %v\n", err)
+		}
+		return nil
+	case "contexts":
+		if len(os.Args) > 2 {
+			return fmt.Errorf("command 'zet contexts' does not need any parameters")
+		}
+		err := cli.indexer.CreateContexts()
+		if err != nil {
+			return fmt.Errorf("Could not create contexts: %v\n", err)
+		}
+		return nil
+	case "fmt":
+		check := len(os.Args) == 3 && os.Args[2] == "--check"
+		if len(os.Args) > 2 && !check {
+			return fmt.Errorf("command 'zet fmt' only takes the parameter '--check'")
+		}
+		renames, err := cli.formatter.Format(check)
+		if err != nil {
+			return fmt.Errorf("Could not format filenames: %v\n", err)
+		}
+		for _, r := range renames {
+			fmt.Println(r)
+		}
+		if check && len(renames) > 0 {
+			return fmt.Errorf("%d filenames are not formatted, run 'zet fmt' to rename them", len(renames))
+		}
+		if check {
+			fmt.Printf("All filenames are formatted")
+			return nil
+		}
+		fmt.Printf("Formatted %d filenames", len(renames))
+		return nil
+	case "backup":
+		if len(os.Args) != 3 {
+			return fmt.Errorf("command 'zet backup' needs a folder or .zip file to write the backup to")
+		}
+		archive, err := cli.backuper.Backup(os.Args[2])
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up your zettelkasten to %v, check it with 'zet backup verify %v'", archive, archive)
+		return nil
+	case "seal":
 		if len(os.Args) > 2 {
-			return fmt.Errorf("command 'zet validate' does not need any parameters")
+			return fmt.Errorf("command 'zet seal' does not need any parameters")
+		}
+		n, err := cli.sealer.Seal()
+		if err != nil {
+			return fmt.Errorf("Could not seal your zettel: %v\n", err)
+		}
+		fmt.Printf("Sealed %d files of your zettel folder in zettel.sha256, 'zet validate' reports changes to them", n)
+		return nil
+	case "validate":
+		format := "text"
+		if len(os.Args) == 4 && os.Args[2] == "--format" {
+			format = os.Args[3]
+		} else if len(os.Args) > 2 {
+			return fmt.Errorf("command 'zet validate' only takes the parameter '--format json|text'")
+		}
+		if format != "json" && format != "text" {
+			return fmt.Errorf("unknown format %q, should be json or text", format)
 		}
 		inconsistencies, err := cli.validator.Val()
 		if err != nil {
-			fmt.Printf("An error ocurred while validating your zettelkasten: %v", err)
+			return fmt.Errorf("An error ocurred while validating your zettelkasten: %v", err)
 		}
-		if len(inconsistencies) > 0 {
-			for _, err := range inconsistencies {
-				fmt.Printf("%v\n", err)
+		if format == "json" {
+			if err := printJSON(inconsistencies); err != nil {
+				return err
 			}
-			return nil
+		} else {
+			printText(inconsistencies)
 		}
+		return exitCode(inconsistencies)
+	default:
+		fmt.Printf("%q is not valid command.\n", os.Args[1])
+		printUsage()
+		return nil
+	}
+}
+
+// The exit codes of zet. 'zet validate' exits with ExitWarnings or ExitErrors, if it found inconsistencies.
+const (
+	ExitClean    = 0
+	ExitFailure  = 1 // zet could not execute the command
+	ExitWarnings = 2 // there are inconsistencies, but only warnings
+	ExitErrors   = 3 // there is at least one inconsistency with the severity error
+)
+
+// ExitError makes zet exit with the code without printing a message.
+type ExitError struct {
+	Code int
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+// exitCode returns an ExitError, if there are inconsistencies.
+// Only inconsistencies with the severity error lead to ExitErrors, all others, also those without a severity,
+// to ExitWarnings.
+func exitCode(inconsistencies []zet.InconErr) error {
+	code := ExitClean
+	for _, i := range inconsistencies {
+		if i.Severity == zet.ErrorSeverity {
+			return ExitError{Code: ExitErrors}
+		}
+		code = ExitWarnings
+	}
+	if code == ExitClean {
+		return nil
+	}
+	return ExitError{Code: code}
+}
+
+func printText(inconsistencies []zet.InconErr) {
+	if len(inconsistencies) == 0 {
 		fmt.Printf(`Your zettelkasten seems to be okay:
 All ids are unique.
 All links point to an existing zettel.
 All ids in the index point to an existing zettel.
 All bibkeys have a corresponding reference.`)
+		return
+	}
+	for _, i := range inconsistencies {
+		fmt.Printf("%v: %v [%v]\n", i.Severity, i, i.Rule)
+	}
+}
 
-		return nil
-	default:
-		fmt.Printf("%q is not valid command.\n", os.Args[1])
-		printUsage()
-		return nil
+type jsonInconsistency struct {
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Message  string   `json:"message"`
+	Files    []string `json:"files"`
+	Id       string   `json:"id,omitempty"`
+}
+
+func printJSON(inconsistencies []zet.InconErr) error {
+	result := []jsonInconsistency{}
+	for _, i := range inconsistencies {
+		files := i.Files
+		if files == nil {
+			files = []string{}
+		}
+		result = append(result, jsonInconsistency{
+			Rule:     i.Rule,
+			Severity: string(i.Severity),
+			Message:  i.Error(),
+			Files:    files,
+			Id:       i.Id,
+		})
+	}
+	j, err := json.MarshalIndent(result, "", "\t")
+	if err != nil {
+		return err
 	}
+	fmt.Println(string(j))
+	return nil
 }
 
 const usage = `Usage: zet <command> [<args>]
       
 These are common zet commands:
+   backup <dest>   Write a compressed backup of your zettelkasten with the checksums of its files into the folder
+                   or .zip file dest, generated folders like 'INDEX' and 'EXPORT' are left out
+   backup verify <archive>
+                   Check all files of a backup against its checksums
+   contexts        Generate folder 'CONTEXTS', which contains for every context declared in contexts.txt the zettel having it
    export		   Generate folder 'EXPORT', which contains files with aggregated data 
+   export refs [<topic>|<id>]
+                   Export the references cited by all zettel, a topic or a chain as BibTeX and CSL-JSON into folder 'EXPORT'
+   fmt [--check]   Rename all zettel to their canonical filename and update folder 'INDEX',
+                   with --check only list them and fail if a filename is not formatted
    import <uri>    Assign filename to textfile(s) under uri (file or folder) and copy them to folder 'zettel
-   index           Generate folder 'INDEX', which contains thematic access points into your zettelkasten
+   import --annotations <file>
+                   Import annotations from a Zotero or Readwise export (.csv or .json) as zettel
+   import --scans <folder>
+                   Import scans (.png, .pdf) with their sidecar textfiles or manifest.csv as zettel
+   index [--dry-run]
+                   Generate or update folder 'INDEX', which contains thematic access points into your zettelkasten,
+                   with --dry-run only list the changes
+   index add <topic> <id>...
+                   Add ids of existing zettel to a topic of your index.txt, the topic gets created if needed
+   index remove <topic> <id>...
+                   Remove ids from a topic of your index.txt
+   index rename <old topic> <new topic>
+                   Rename a topic of your index.txt including all references to it
    init            Creates an empty zettelkasten
    init example    Downloads an example zettelkasten which is a tutorial
-   validate        Check your zettelkasten's consistency
+   refs            Generate folder 'REFERENCES', which contains for every reference the citing zettel sorted by location
+   refs import <file>
+                   Add the references of a RIS (.ris) or EndNote XML (.xml) file to your references.bib
+   restore <archive> <dest>
+                   Restore a verified backup into the empty folder dest and check the restored files
+   seal            Record the checksums of all files in folder 'zettel' in zettel.sha256,
+                   'zet validate' then reports files changed, missing or added without 'zet import'
+   validate [--format json|text]
+                   Check your zettelkasten's consistency, exits with 0 if it is clean,
+                   2 if there are only warnings and 3 if there are errors
 
 All Zet commands operate read-only on the three elements of the zettelkasten:
   * index.txt        (contains manually created starting points into your zettelkasten)
   * folder 'zettel'  (contains all zettel as a .txt, .png or .pdf file)
-  * references.bib   (contains information on sources - needed especially for scientific writing)`
+  * references.bib   (contains information on sources - needed especially for scientific writing)
+                     and/or folder 'bibliography' with further .bib files, e.g. a shared and a personal bibliography`
 
 func printUsage() {
 	fmt.Printf(usage)
 }
 
 const (
-	ZettelFolder   = "zettel"
-	ReferencesFile = "references.bib"
-	IndexFile      = "index.txt"
+	ZettelFolder       = "zettel"
+	ReferencesFile     = "references.bib"
+	BibliographyFolder = "bibliography"
+	IndexFile          = "index.txt"
 )
 
-// isCalledFromZetDir checks if a folder "zettel" and two files references.bib and index.txt exist
+// isCalledFromZetDir checks if a folder "zettel", the file index.txt and
+// the file references.bib or the folder "bibliography" exist.
 // If one of these are non-existent it assumes that the user is not in his zettelkasten directory.
 func isCalledFromZetDir() bool {
 	_, err := os.Stat(ZettelFolder)
 	_, err2 := os.Stat(ReferencesFile)
 	_, err3 := os.Stat(IndexFile)
-	if os.IsNotExist(err) || os.IsNotExist(err2) || os.IsNotExist(err3) {
+	_, err4 := os.Stat(BibliographyFolder)
+	if os.IsNotExist(err) || (os.IsNotExist(err2) && os.IsNotExist(err4)) || os.IsNotExist(err3) {
 		return false
 	}
 	return true
@@ -0,0 +1,141 @@
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/crelder/zet"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+)
+
+// linker creates the links of views upon your zettelkasten according to a link strategy.
+type linker struct {
+	strategy zet.LinkStrategy
+}
+
+func newLinker(s zet.LinkStrategy) linker {
+	if s == "" {
+		s = zet.HardlinkStrategy
+	}
+	return linker{strategy: s}
+}
+
+// name returns the filename of a link, shortcut files get their extension appended,
+// e.g. "000 190119e - Komplexität.txt.url".
+func (l linker) name(linkName string) string {
+	switch l.strategy {
+	case zet.URLShortcutStrategy:
+		return linkName + ".url"
+	case zet.DesktopShortcutStrategy:
+		return linkName + ".desktop"
+	}
+	return linkName
+}
+
+// create links newname to the file, replacing a file existing under newname.
+func (l linker) create(file, newname string) error {
+	if err := existsOrMake(filepath.Dir(newname)); err != nil {
+		return err
+	}
+	if err := os.Remove(newname); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	switch l.strategy {
+	case zet.SymlinkStrategy:
+		rel, err := filepath.Rel(filepath.Dir(newname), file)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(rel, newname)
+	case zet.CopyStrategy:
+		return copyFile(file, newname)
+	case zet.URLShortcutStrategy, zet.DesktopShortcutStrategy:
+		content, err := l.shortcut(file)
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(newname, content, 0644)
+	}
+	return os.Link(file, newname)
+}
+
+// isLinkTo checks if the file p is a link to the file as if it was created under newname.
+// Links that would have a different content under newname, like relative symlinks, don't match.
+func (l linker) isLinkTo(p, newname, file string) bool {
+	info, err := os.Lstat(p)
+	if err != nil {
+		return false
+	}
+	target, err := os.Stat(file)
+	if err != nil {
+		return false
+	}
+
+	switch l.strategy {
+	case zet.SymlinkStrategy:
+		if info.Mode()&fs.ModeSymlink == 0 {
+			return false
+		}
+		dest, err := os.Readlink(p)
+		rel, err2 := filepath.Rel(filepath.Dir(newname), file)
+		return err == nil && err2 == nil && dest == rel
+	case zet.CopyStrategy:
+		// A copy keeps the modification time of the zettel.
+		return info.Mode().IsRegular() && !os.SameFile(info, target) &&
+			info.Size() == target.Size() && info.ModTime().Equal(target.ModTime())
+	case zet.URLShortcutStrategy, zet.DesktopShortcutStrategy:
+		content, err := l.shortcut(file)
+		if err != nil {
+			return false
+		}
+		f, err := os.ReadFile(p)
+		return err == nil && bytes.Equal(f, content)
+	}
+	return info.Mode().IsRegular() && os.SameFile(info, target)
+}
+
+// shortcut returns the content of a shortcut file opening the file.
+func (l linker) shortcut(file string) ([]byte, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(abs)}
+
+	if l.strategy == zet.URLShortcutStrategy {
+		return []byte(fmt.Sprintf("[InternetShortcut]\r\nURL=%v\r\n", u.String())), nil
+	}
+	return []byte(fmt.Sprintf("[Desktop Entry]\nType=Link\nName=%v\nURL=%v\n", filepath.Base(file), u.String())), nil
+}
+
+// copyFile copies the file and keeps its modification time.
+func copyFile(file, newname string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.Create(newname)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	return os.Chtimes(newname, info.ModTime(), info.ModTime())
+}
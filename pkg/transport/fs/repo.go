@@ -1,6 +1,8 @@
 package fs
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"github.com/crelder/zet"
@@ -12,8 +14,15 @@ import (
 	"strings"
 )
 
+const (
+	indexFile          = "index.txt"
+	referencesFile     = "references.bib"
+	bibliographyFolder = "bibliography" // contains further .bib files, e.g. a shared and a personal bibliography
+)
+
 // Repo allows access to the content of your zettelkasten.
-// Repo satisfies the zet.Repo, index.Indexer, export.Exporter and imports.Reader interface.
+// Repo satisfies the zet.Repo, index.Indexer, export.Exporter, imports.Reader, validate.ContentReader,
+// validate.SealReader and seal.Store interface.
 // path represents the path to the directory, where your zettelkasten lies.
 type Repo struct {
 	parser zet.Parser
@@ -115,6 +124,7 @@ func (r Repo) getFiles() ([]zettelFile, []zet.InconErr, error) {
 	if err != nil {
 		return nil, nil, fmt.Errorf("fs: %v", err)
 	}
+	scans := getScans(dirEntries)
 	var zettelFiles []zettelFile
 	var parseErr error
 	var z zet.Zettel
@@ -123,10 +133,13 @@ func (r Repo) getFiles() ([]zettelFile, []zet.InconErr, error) {
 		if visibleFile(file) {
 			continue
 		}
+		if isTranscription(file.Name(), scans) {
+			continue
+		}
 
 		z, parseErr = r.parser.Filename(file.Name())
 		if parseErr != nil {
-			parseErrors = append(parseErrors, zet.InconErr{parseErr})
+			parseErrors = append(parseErrors, zet.InconErr{Message: parseErr, Rule: zet.FilenameSyntaxRule, Severity: zet.ErrorSeverity, Files: []string{"zettel/" + file.Name()}})
 			continue
 		}
 		zettelFiles = append(zettelFiles, zettelFile{
@@ -139,6 +152,25 @@ func (r Repo) getFiles() ([]zettelFile, []zet.InconErr, error) {
 	return zettelFiles, parseErrors, nil
 }
 
+// getScans returns all filenames of scans without their file extension.
+func getScans(dirEntries []os.DirEntry) map[string]bool {
+	scans := make(map[string]bool)
+	for _, file := range dirEntries {
+		ext := strings.ToLower(filepath.Ext(file.Name()))
+		if ext == ".png" || ext == ".pdf" {
+			scans[strings.TrimSuffix(file.Name(), filepath.Ext(file.Name()))] = true
+		}
+	}
+	return scans
+}
+
+// isTranscription checks if the file is a textfile that has the same filename as a scan.
+// Such a textfile holds the transcription of the scan and therefore belongs to the zettel of the scan.
+func isTranscription(filename string, scans map[string]bool) bool {
+	ext := filepath.Ext(filename)
+	return ext == ".txt" && scans[strings.TrimSuffix(filename, ext)]
+}
+
 // visibleFile checks if the filename is valid. Only visible files are valid.
 // Therefore, the check currently only works on unix systems.
 func visibleFile(file os.DirEntry) bool {
@@ -158,25 +190,205 @@ func visibleFile(file os.DirEntry) bool {
 // ParsingErrors are returned with the second parameter []error.
 // All other errors via the last parameter.
 func (r Repo) GetIndex() (zet.Index, []zet.InconErr, error) {
-	var index map[string][]string
-	f, err := os.ReadFile(r.path + "/index.txt")
+	f, err := r.GetIndexContent()
 	if err != nil {
-		return nil, nil, fmt.Errorf("fs: %v", err)
+		return nil, nil, err
+	}
+
+	index, parseErrors := r.parser.Index(f)
+
+	return index, withFile(parseErrors, indexFile), nil
+
+}
+
+// GetIndexContent returns the unparsed content of your index.txt.
+func (r Repo) GetIndexContent() (string, error) {
+	f, err := os.ReadFile(path.Join(r.path, indexFile))
+	if err != nil {
+		return "", fmt.Errorf("fs: %v", err)
 	}
+	return string(f), nil
+}
 
-	index, parseErrors := r.parser.Index(string(f))
+// SaveIndex replaces the content of your index.txt.
+// The content is written to a temporary file first, so that an interrupted write doesn't destroy your index.
+func (r Repo) SaveIndex(content string) error {
+	p := path.Join(r.path, indexFile)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, []byte(content), 0644); err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	return nil
+}
 
-	return index, parseErrors, nil
+// GetZettelContent returns the content of the zettel file with the name, e.g. "170224a - Go.txt".
+func (r Repo) GetZettelContent(name string) (string, error) {
+	f, err := os.ReadFile(path.Join(r.path, "zettel", name))
+	if err != nil {
+		return "", fmt.Errorf("fs: %v", err)
+	}
+	return string(f), nil
+}
 
+// withFile adds the file, e.g. "index.txt", to the inconsistencies found while parsing it.
+func withFile(incons []zet.InconErr, file string) []zet.InconErr {
+	for i := range incons {
+		incons[i].Files = []string{file}
+	}
+	return incons
 }
 
+// GetBibkeys returns the bibkeys of all references files of your zettelkasten.
 func (r Repo) GetBibkeys() ([]string, error) {
-	f, err := os.ReadFile(r.path + "/references.bib")
+	rfs, err := r.getReferenceFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	var bibkeys []string
+	for _, rf := range rfs {
+		bibkeys = append(bibkeys, r.parser.Reference(rf.content)...)
+	}
+	return bibkeys, nil
+}
+
+// GetReferences returns all entries of the references files of your zettelkasten.
+// ParsingErrors are returned with the second parameter []error. Parsing errors in a file of the folder 'bibliography'
+// start with the name of the file, e.g. "bibliography/shared.bib: ".
+// All other errors via the last parameter.
+func (r Repo) GetReferences() ([]zet.BibEntry, []zet.InconErr, error) {
+	rfs, err := r.getReferenceFiles()
 	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []zet.BibEntry
+	var parseErrors []zet.InconErr
+	for _, rf := range rfs {
+		e, pe := r.parser.Bibliography(rf.content)
+		for n := range e {
+			e[n].File = rf.name
+		}
+		entries = append(entries, e...)
+
+		for _, p := range pe {
+			if rf.name != referencesFile {
+				p.Message = fmt.Errorf("%v: %w", rf.name, p.Message)
+			}
+			parseErrors = append(parseErrors, withFile([]zet.InconErr{p}, rf.name)...)
+		}
+	}
+
+	return entries, parseErrors, nil
+}
+
+type referenceFile struct {
+	name    string // e.g. "references.bib" or "bibliography/shared.bib"
+	content string
+}
+
+// getReferenceFiles returns the file references.bib and all .bib files in the folder 'bibliography'
+// sorted by their name. At least one of both must exist.
+func (r Repo) getReferenceFiles() ([]referenceFile, error) {
+	var rfs []referenceFile
+
+	f, err := os.ReadFile(path.Join(r.path, referencesFile))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("fs: %v", err)
+	}
+	fileExists := err == nil
+	if fileExists {
+		rfs = append(rfs, referenceFile{name: referencesFile, content: string(f)})
+	}
+
+	dirEntries, err := os.ReadDir(path.Join(r.path, bibliographyFolder))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
 		return nil, fmt.Errorf("fs: %v", err)
 	}
+	if err != nil && !fileExists {
+		return nil, fmt.Errorf("fs: neither %v nor folder '%v' exist", referencesFile, bibliographyFolder)
+	}
+
+	// os.ReadDir returns the entries sorted by filename.
+	for _, de := range dirEntries {
+		if de.IsDir() || filepath.Ext(de.Name()) != ".bib" {
+			continue
+		}
+		name := bibliographyFolder + "/" + de.Name()
+		f, err := os.ReadFile(path.Join(r.path, name))
+		if err != nil {
+			return nil, fmt.Errorf("fs: %v", err)
+		}
+		rfs = append(rfs, referenceFile{name: name, content: string(f)})
+	}
+
+	return rfs, nil
+}
+
+// GetConfig returns the settings of your zettelkasten from the optional file config.txt.
+// If the file doesn't exist, the default settings are returned.
+// ParsingErrors are returned with the second parameter []error.
+// All other errors via the last parameter.
+func (r Repo) GetConfig() (zet.Config, []zet.InconErr, error) {
+	f, err := os.ReadFile(r.path + "/config.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return zet.Config{}, nil, fmt.Errorf("fs: %v", err)
+	}
+
+	config, parseErrors := r.parser.Config(string(f))
+
+	return config, withFile(parseErrors, "config.txt"), nil
+}
+
+// AddReferences appends the BibTeX entries to the references.bib of your zettelkasten.
+// The existing entries are not changed. If there is no references.bib, e.g. since you only use the folder
+// 'bibliography', it gets created.
+func (r Repo) AddReferences(bibtex string) error {
+	p := path.Join(r.path, referencesFile)
+	f, err := os.ReadFile(p)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("fs: %v", err)
+	}
+
+	var sep string
+	switch {
+	case len(f) == 0:
+	case strings.HasSuffix(string(f), "\n\n"):
+	case strings.HasSuffix(string(f), "\n"):
+		sep = "\n"
+	default:
+		sep = "\n\n"
+	}
+
+	file, err := os.OpenFile(p, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(sep + bibtex + "\n")
+	if err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	return nil
+}
+
+// GetContexts returns the contexts declared in the optional file contexts.txt.
+// If the file doesn't exist, no contexts are returned.
+// ParsingErrors are returned with the second parameter []error.
+// All other errors via the last parameter.
+func (r Repo) GetContexts() ([]zet.ContextEntry, []zet.InconErr, error) {
+	f, err := os.ReadFile(r.path + "/contexts.txt")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, fmt.Errorf("fs: %v", err)
+	}
+
+	contexts, parseErrors := r.parser.Contexts(string(f))
 
-	return r.parser.Reference(string(f)), nil
+	return contexts, withFile(parseErrors, "contexts.txt"), nil
 }
 
 // CreateInfo persists some statistics in form of a txt file about a topic like e.g. keywords, context or literature.
@@ -186,7 +398,12 @@ func (r Repo) PersistInfo(m map[string][]string) error {
 		return fmt.Errorf("repo: %v", err)
 	}
 
-	err = existsOrMake(r.path + "/EXPORT")
+	return r.AddInfo(m)
+}
+
+// AddInfo writes the files into the folder 'EXPORT' without removing the files already in there.
+func (r Repo) AddInfo(m map[string][]string) error {
+	err := existsOrMake(r.path + "/EXPORT")
 	if err != nil {
 		return err
 	}
@@ -202,21 +419,6 @@ func (r Repo) PersistInfo(m map[string][]string) error {
 	return nil
 }
 
-func (r Repo) getFilePath(id string) (string, error) {
-	zfs, _, err := r.getFiles()
-	if err != nil {
-		return "", err
-	}
-	for _, zf := range zfs {
-		if id == zf.zettel.Id {
-			result := zf.path + "/" + zf.filename
-			return result, nil
-		}
-
-	}
-	return "", fmt.Errorf("id not found: %v", id)
-}
-
 func existsOrMake(dir string) error {
 	err := os.MkdirAll(dir, 0755)
 	if err != nil {
@@ -226,34 +428,257 @@ func existsOrMake(dir string) error {
 	return nil
 }
 
-// CreateFolgezettelStruct creates a tree like link structure, so called "Folgezettel" in the fs.
-// This represents the physical representation how Niklas Luhmann arranged his Zettel in his
-// wooden zettelkasten boxes. This is used for creating chains of thoughts.
-// Topic is e.g. "Evolution" and the map contains all links[linkname]targetId
-func (r Repo) PersistIndex(links map[string]string) error {
+// PersistView brings the view upon your zettelkasten in the folder, e.g. "INDEX", up to date.
+// links contains all links[linkname]targetId and files all additional textfiles[filename]content,
+// both relative to the folder.
+//
+// Only what changed is touched: missing links and files are added, links to a zettel that moved get
+// renamed, outdated links and files are updated and all other files of a type a view writes are removed,
+// as well as empty folders. Other files, e.g. a .bib file, are kept.
+// It returns the changes, sorted by path. With dryRun, the changes are only returned, not made.
+//
+// The links are created with the link strategy of your config.txt, hardlinks by default.
+func (r Repo) PersistView(folder string, links map[string]string, files map[string]string, dryRun bool) ([]string, error) {
+	viewPath := path.Join(r.path, folder)
+
+	config, _, err := r.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	l := newLinker(config.LinkStrategy)
+
+	filePaths, err := r.getFilePaths()
+	if err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string) // targets[linkName]path of the zettel
 	for linkName, targetId := range links {
+		fp, ok := filePaths[targetId]
+		if !ok {
+			return nil, fmt.Errorf("id not found: %v", targetId)
+		}
+		targets[l.name(linkName)] = fp
+	}
+
+	existing, err := getViewFiles(viewPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []string
+	var ops []func() error
+	change := func(description string, op func() error) {
+		changes = append(changes, description)
+		ops = append(ops, op)
+	}
+
+	// Links, which point to a zettel no longer in their place, can be renamed.
+	// Files with an extension a view never writes, e.g. a .bib file, are not removed.
+	extensions := make(map[string]bool)
+	for ext := range viewExtensions {
+		extensions[ext] = true
+	}
+	for name := range files {
+		extensions[strings.ToLower(filepath.Ext(name))] = true
+	}
+	obsolete := make(map[string]bool)
+	for name := range existing {
+		if _, ok := targets[name]; ok {
+			continue
+		}
+		if _, ok := files[name]; ok {
+			continue
+		}
+		if !extensions[strings.ToLower(filepath.Ext(name))] {
+			continue
+		}
+		obsolete[name] = true
+	}
+
+	for _, name := range sortedKeys(targets) {
+		newname := path.Join(viewPath, name)
+		fp := targets[name]
+		if existing[name] {
+			if l.isLinkTo(newname, newname, fp) {
+				continue
+			}
+			change("update "+path.Join(folder, name), func() error {
+				return l.create(fp, newname)
+			})
+			continue
+		}
+		if oldname, ok := findLink(l, viewPath, obsolete, newname, fp); ok {
+			delete(obsolete, oldname)
+			oldpath := path.Join(viewPath, oldname)
+			change("rename "+path.Join(folder, oldname)+" -> "+path.Join(folder, name), func() error {
+				return move(oldpath, newname)
+			})
+			continue
+		}
+		change("add "+path.Join(folder, name), func() error {
+			return l.create(fp, newname)
+		})
+	}
+
+	for _, name := range sortedKeys(files) {
+		newname := path.Join(viewPath, name)
+		content := files[name]
+		action := "add "
+		if existing[name] {
+			f, err := os.ReadFile(newname)
+			if err == nil && string(f) == content {
+				continue
+			}
+			action = "update "
+		}
+		change(action+path.Join(folder, name), func() error {
+			if err := existsOrMake(filepath.Dir(newname)); err != nil {
+				return err
+			}
+			return os.WriteFile(newname, []byte(content), 0644)
+		})
+	}
 
-		fp, err := r.getFilePath(targetId)
+	for _, name := range sortedNames(obsolete) {
+		oldpath := path.Join(viewPath, name)
+		change("remove "+path.Join(folder, name), func() error {
+			return os.Remove(oldpath)
+		})
+	}
+
+	if dryRun {
+		sort.Strings(changes)
+		return changes, nil
+	}
+	for _, op := range ops {
+		if err := op(); err != nil {
+			return nil, fmt.Errorf("fs: %v", err)
+		}
+	}
+	if err := removeEmptyFolders(viewPath); err != nil {
+		return nil, err
+	}
+	sort.Strings(changes)
+	return changes, nil
+}
+
+// viewExtensions are the extensions of the files a view writes: the extensions of zettel, of shortcut files
+// and of summaries, so that outdated summaries are removed even if a view has none left.
+var viewExtensions = map[string]bool{".txt": true, ".png": true, ".pdf": true, ".url": true, ".desktop": true, ".md": true}
+
+// getViewFiles returns the paths of all files within the folder of a view relative to the folder.
+// A folder which doesn't exist yet has no files.
+func getViewFiles(viewPath string) (map[string]bool, error) {
+	files := make(map[string]bool)
+	err := filepath.WalkDir(viewPath, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == viewPath {
+				return nil
+			}
 			return err
 		}
-
-		oldname := fp
-		newname := path.Join(r.path, linkName)
-		dir, _ := filepath.Split(newname)
-		err = existsOrMake(dir)
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(viewPath, p)
 		if err != nil {
 			return err
 		}
+		files[filepath.ToSlash(rel)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fs: %v", err)
+	}
+	return files, nil
+}
+
+// findLink returns the name of the file within the folder of a view, which can be renamed to newname,
+// since it is a link to the file.
+func findLink(l linker, viewPath string, names map[string]bool, newname, file string) (string, bool) {
+	for _, name := range sortedNames(names) {
+		if l.isLinkTo(path.Join(viewPath, name), newname, file) {
+			return name, true
+		}
+	}
+	return "", false
+}
 
-		err = os.Link(oldname, newname)
+func move(oldpath, newpath string) error {
+	if err := existsOrMake(filepath.Dir(newpath)); err != nil {
+		return err
+	}
+	return os.Rename(oldpath, newpath)
+}
+
+// removeEmptyFolders removes all empty folders within the folder of a view, but not the folder itself.
+func removeEmptyFolders(viewPath string) error {
+	var dirs []string
+	err := filepath.WalkDir(viewPath, func(p string, d fs.DirEntry, err error) error {
 		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && p == viewPath {
+				return nil
+			}
 			return err
 		}
+		if d.IsDir() && p != viewPath {
+			dirs = append(dirs, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+
+	// Subfolders come after their parent folder, so they are removed first.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		entries, err := os.ReadDir(dirs[i])
+		if err != nil {
+			return fmt.Errorf("fs: %v", err)
+		}
+		if len(entries) == 0 {
+			if err := os.Remove(dirs[i]); err != nil {
+				return fmt.Errorf("fs: %v", err)
+			}
+		}
 	}
 	return nil
 }
 
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedNames(m map[string]bool) []string {
+	var names []string
+	for n := range m {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getFilePaths returns the paths of all zettel files as map[id]path.
+func (r Repo) getFilePaths() (map[string]string, error) {
+	zfs, _, err := r.getFiles()
+	if err != nil {
+		return nil, err
+	}
+	filePaths := make(map[string]string)
+	for _, zf := range zfs {
+		if _, ok := filePaths[zf.zettel.Id]; ok {
+			continue
+		}
+		filePaths[zf.zettel.Id] = zf.path + "/" + zf.filename
+	}
+	return filePaths, nil
+}
+
 // GetContents reads the files in the path and extracts from allowed files the text content.
 func (r Repo) GetContents(uri string) ([]string, error) {
 	var contents []string
@@ -289,6 +714,40 @@ func (r Repo) GetContents(uri string) ([]string, error) {
 	return contents, nil
 }
 
+// GetFiles reads the raw data of the file under uri or of all visible files in the folder under uri.
+// It returns map[filename]data.
+func (r Repo) GetFiles(uri string) (map[string][]byte, error) {
+	fileInfo, err := os.Stat(uri)
+	if err != nil {
+		return nil, fmt.Errorf("fs: couldn't open uri %q", uri)
+	}
+
+	if !fileInfo.IsDir() {
+		dat, err := os.ReadFile(uri)
+		if err != nil {
+			return nil, fmt.Errorf("fs: %v", err)
+		}
+		return map[string][]byte{fileInfo.Name(): dat}, nil
+	}
+
+	dirEntries, err := os.ReadDir(uri)
+	if err != nil {
+		return nil, fmt.Errorf("fs: %v", err)
+	}
+	files := make(map[string][]byte)
+	for _, entry := range dirEntries {
+		if entry.IsDir() || visibleFile(entry) {
+			continue
+		}
+		dat, err := os.ReadFile(filepath.Join(uri, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("fs: %v", err)
+		}
+		files[entry.Name()] = dat
+	}
+	return files, nil
+}
+
 func exists(path string) bool {
 	f, err := os.Open(path)
 	defer f.Close()
@@ -315,9 +774,10 @@ func isAllowed(fn string) bool {
 	return false
 }
 
-// Save creates text files with a valid filename and the content.
+// Save creates files with a valid filename and the content, e.g. text zettel or scans.
 // The parameter expects map[filename]content.
-func (r Repo) Save(zfs map[string]string) (int, error) {
+// If your zettelkasten is sealed, the checksums of the files are added to zettel.sha256.
+func (r Repo) Save(zfs map[string][]byte) (int, error) {
 	impPath := r.path + "/zettel"
 
 	if exists(impPath) == false {
@@ -328,11 +788,53 @@ func (r Repo) Save(zfs map[string]string) (int, error) {
 	counter := 0
 	for filename, content := range zfs {
 		filename := impPath + "/" + filename
-		err := os.WriteFile(filename, []byte(content), 0644)
+		err := os.WriteFile(filename, content, 0644)
 		if err != nil {
 			return counter, fmt.Errorf("could not write file %q: %v", filename, err)
 		}
 		counter++
 	}
+
+	err := r.updateSeal(func(seal map[string]string) {
+		for filename, content := range zfs {
+			sum := sha256.Sum256(content)
+			seal[filename] = hex.EncodeToString(sum[:])
+		}
+	})
+	if err != nil {
+		return counter, err
+	}
 	return len(zfs), nil
 }
+
+// RenameZettel renames a file in the folder 'zettel'. The transcription of a scan, a textfile with the same
+// name as the scan, is renamed with it. If your zettelkasten is sealed, zettel.sha256 is updated as well.
+func (r Repo) RenameZettel(oldName, newName string) error {
+	zettelPath := path.Join(r.path, "zettel")
+	if exists(path.Join(zettelPath, newName)) {
+		return fmt.Errorf("fs: can't rename %q to %q, the file already exists", oldName, newName)
+	}
+	if err := os.Rename(path.Join(zettelPath, oldName), path.Join(zettelPath, newName)); err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	renames := map[string]string{oldName: newName}
+
+	oldExt, newExt := filepath.Ext(oldName), filepath.Ext(newName)
+	transcription := strings.TrimSuffix(oldName, oldExt) + ".txt"
+	if oldExt != ".txt" && exists(path.Join(zettelPath, transcription)) {
+		newTranscription := strings.TrimSuffix(newName, newExt) + ".txt"
+		if err := os.Rename(path.Join(zettelPath, transcription), path.Join(zettelPath, newTranscription)); err != nil {
+			return fmt.Errorf("fs: %v", err)
+		}
+		renames[transcription] = newTranscription
+	}
+
+	return r.updateSeal(func(seal map[string]string) {
+		for o, n := range renames {
+			if c, ok := seal[o]; ok {
+				delete(seal, o)
+				seal[n] = c
+			}
+		}
+	})
+}
@@ -0,0 +1,115 @@
+package fs
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// sealFile holds the checksums of your zettel files in the format of sha256sum,
+// so it can also be checked with 'sha256sum -c zettel.sha256' within your zettelkasten.
+const sealFile = "zettel.sha256"
+
+// GetChecksums returns the SHA-256 checksum of every file in the folder 'zettel' (map[filename]checksum).
+// Invisible files are skipped.
+func (r Repo) GetChecksums() (map[string]string, error) {
+	dirEntries, err := os.ReadDir(path.Join(r.path, "zettel"))
+	if err != nil {
+		return nil, fmt.Errorf("fs: %v", err)
+	}
+	checksums := make(map[string]string)
+	for _, e := range dirEntries {
+		if e.IsDir() || visibleFile(e) {
+			continue
+		}
+		c, err := checksum(path.Join(r.path, "zettel", e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		checksums[e.Name()] = c
+	}
+	return checksums, nil
+}
+
+// GetSeal returns the checksums recorded in zettel.sha256 (map[filename]checksum).
+// If your zettelkasten is not sealed, it returns false.
+func (r Repo) GetSeal() (map[string]string, bool, error) {
+	f, err := os.ReadFile(path.Join(r.path, sealFile))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("fs: %v", err)
+	}
+
+	seal := make(map[string]string)
+	for n, line := range strings.Split(string(f), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// Lines are 'checksum  zettel/filename', sha256sum marks binary files with 'checksum *zettel/filename'.
+		i := strings.Index(line, " ")
+		if i == -1 || len(line) < i+2 {
+			return nil, false, fmt.Errorf("fs: %v line %d: could not parse %q", sealFile, n+1, line)
+		}
+		name := strings.TrimPrefix(line[i+2:], "zettel/")
+		seal[name] = line[:i]
+	}
+	return seal, true, nil
+}
+
+// SaveSeal writes the checksums (map[filename]checksum) of your zettel files into zettel.sha256.
+func (r Repo) SaveSeal(seal map[string]string) error {
+	var names []string
+	for name := range seal {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		fmt.Fprintf(&b, "%v  zettel/%v\n", seal[name], name)
+	}
+
+	p := path.Join(r.path, sealFile)
+	tmp := p + ".tmp"
+	if err := os.WriteFile(tmp, []byte(b.String()), 0644); err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	if err := os.Rename(tmp, p); err != nil {
+		return fmt.Errorf("fs: %v", err)
+	}
+	return nil
+}
+
+// updateSeal changes the seal of a sealed zettelkasten with the function update.
+// If your zettelkasten is not sealed, nothing happens.
+func (r Repo) updateSeal(update func(seal map[string]string)) error {
+	seal, sealed, err := r.GetSeal()
+	if err != nil || !sealed {
+		return err
+	}
+	update(seal)
+	return r.SaveSeal(seal)
+}
+
+func checksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", fmt.Errorf("fs: %v", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("fs: %v", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"strings"
+)
+
+// maxTypos is the maximum number of typos (edit distance) for which an unknown context counts as misspelled.
+const maxTypos = 2
+
+// validateContexts returns all contexts of zettel that are not declared in your contexts.txt.
+// If the context is close to a declared name or alias, the inconsistency suggests it as the correct spelling.
+// Without declared contexts, contexts are free strings and not validated.
+func validateContexts(zettel []zet.Zettel, contexts []zet.ContextEntry) []zet.InconErr {
+	if len(contexts) == 0 {
+		return nil
+	}
+
+	var incons []zet.InconErr
+	for _, z := range zettel {
+		for _, context := range z.Context {
+			if isDeclared(context, contexts) {
+				continue
+			}
+			if suggestion := getSuggestion(context, contexts); suggestion != "" {
+				incons = append(incons, zet.InconErr{
+					Message:  fmt.Errorf("context: unknown context %q in zettel %v, did you mean %q?", context, z.Id, suggestion),
+					Rule:     zet.UnknownContextRule,
+					Severity: zet.WarningSeverity,
+					Files:    []string{zettelFile(z)},
+					Id:       z.Id,
+				})
+				continue
+			}
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("context: unknown context %q in zettel %v", context, z.Id),
+				Rule:     zet.UnknownContextRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+		}
+	}
+	return incons
+}
+
+func isDeclared(context string, contexts []zet.ContextEntry) bool {
+	for _, c := range contexts {
+		if c.Matches(context) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSuggestion returns the declared name or alias closest to the context, if it has at most maxTypos typos.
+func getSuggestion(context string, contexts []zet.ContextEntry) string {
+	var suggestion string
+	minDistance := maxTypos + 1
+	for _, c := range contexts {
+		for _, name := range append([]string{c.Name}, c.Aliases...) {
+			d := distance(strings.ToLower(context), strings.ToLower(name))
+			if d < minDistance {
+				suggestion, minDistance = name, d
+			}
+		}
+	}
+	return suggestion
+}
+
+// distance returns the Levenshtein distance of a and b, i.e. the number of inserted, deleted or
+// replaced characters to change a into b.
+func distance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur := make([]int, len(rb)+1)
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(rb)]
+}
+
+func minInt(n ...int) int {
+	m := n[0]
+	for _, e := range n[1:] {
+		if e < m {
+			m = e
+		}
+	}
+	return m
+}
@@ -0,0 +1,184 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// allowedExtensions are the file types of a zettel, textfiles and scans.
+var allowedExtensions = map[string]bool{".txt": true, ".png": true, ".pdf": true}
+
+// FilenameParser parses and formats the filenames of zettel.
+type FilenameParser interface {
+	Filename(string) (zet.Zettel, error)
+	Format(zet.Zettel) (string, error)
+	IsId(s string) bool
+}
+
+// validateFilenames checks the filenames of all zettel against the format
+//
+//	ID - Keywords - Contexts, References - Link.ext
+//
+// where keywords start with a capital letter, references come after contexts, all are separated by ', ',
+// the link is the id of the predecessor and the extension is one of .txt, .png or .pdf.
+// Filenames that can't be parsed at all are already reported while reading the zettel.
+func validateFilenames(zettel []zet.Zettel, p FilenameParser) []zet.InconErr {
+	var incons []zet.InconErr
+	for _, z := range zettel {
+		corrected, problems := lintFilename(z.Name, p)
+		if len(problems) == 0 {
+			continue
+		}
+		message := fmt.Errorf("filename: %q has %v, rename it to %q", z.Name, strings.Join(problems, ", "), corrected)
+		if corrected == z.Name {
+			message = fmt.Errorf("filename: %q has %v", z.Name, strings.Join(problems, ", "))
+		}
+		incons = append(incons, zet.InconErr{
+			Message:  message,
+			Rule:     zet.FilenameFormatRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{zettelFile(z)},
+			Id:       z.Id,
+		})
+	}
+	return incons
+}
+
+// lintFilename returns the filename corrected as far as possible and the problems it found.
+func lintFilename(name string, p FilenameParser) (string, []string) {
+	var problems []string
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	if !allowedExtensions[ext] {
+		if allowedExtensions[strings.ToLower(ext)] {
+			problems = append(problems, "an upper-case extension")
+			ext = strings.ToLower(ext)
+		} else {
+			problems = append(problems, fmt.Sprintf("the extension %q, which is not one of .txt, .png or .pdf", ext))
+		}
+	}
+
+	if collapsed := strings.Join(strings.Fields(base), " "); collapsed != base {
+		problems = append(problems, "superfluous spaces")
+		base = collapsed
+	}
+
+	parts := strings.Split(base, " - ")
+	id := parts[0]
+	if i := strings.Index(id, " "); i != -1 {
+		problems = append(problems, "no ' - ' after the id")
+		parts = append([]string{id[:i], id[i+1:]}, parts[1:]...)
+		id = parts[0]
+	}
+
+	// The slots of the filename, see parse.Filename.
+	var keywords, context, link string
+	switch len(parts) {
+	case 1:
+	case 2:
+		if p.IsId(parts[1]) {
+			link = parts[1]
+		} else {
+			keywords = parts[1]
+		}
+	case 3:
+		keywords = parts[1]
+		if p.IsId(parts[2]) {
+			link = parts[2]
+		} else {
+			context = parts[2]
+		}
+	default:
+		keywords, context, link = parts[1], parts[2], strings.Join(parts[3:], " - ")
+	}
+
+	keywordList, hasEmpty := splitList(keywords)
+	contextList, hasEmpty2 := splitList(context)
+	if hasEmpty || hasEmpty2 {
+		problems = append(problems, "a trailing or empty ','")
+	}
+
+	for i, k := range keywordList {
+		if r, size := utf8.DecodeRuneInString(k); unicode.IsLower(r) {
+			problems = append(problems, fmt.Sprintf("the lower-case keyword %q", k))
+			keywordList[i] = string(unicode.ToUpper(r)) + k[size:]
+		}
+	}
+
+	var contexts []string
+	for _, c := range contextList {
+		if p.IsId(c) && link == "" {
+			problems = append(problems, fmt.Sprintf("the predecessor %v in the references/context part", c))
+			link = c
+			continue
+		}
+		contexts = append(contexts, c)
+	}
+
+	if link != "" && !p.IsId(link) {
+		problems = append(problems, fmt.Sprintf("%q in the place of the predecessor id", link))
+	}
+
+	corrected := id
+	if len(keywordList) > 0 {
+		corrected += " - " + strings.Join(keywordList, ", ")
+	}
+	if len(contexts) > 0 {
+		corrected += " - " + strings.Join(contexts, ", ")
+	}
+	if link != "" {
+		corrected += " - " + link
+	}
+
+	// The filename is corrected to the canonical filename of 'zet fmt', so that both agree.
+	// A filename with an invalid predecessor id is left as it is, since the predecessor would get lost.
+	if z, err := p.Filename(corrected + ext); err == nil && z.Predecessor == link {
+		if hasReferenceFirst(contexts, z.References) {
+			problems = append(problems, "references before contexts")
+		}
+		if formatted, err := p.Format(z); err == nil {
+			return formatted, problems
+		}
+	}
+	return corrected + ext, problems
+}
+
+// hasReferenceFirst checks if a reference comes before a context in the references/context part of a filename.
+func hasReferenceFirst(contexts []string, references []zet.Reference) bool {
+	isReference := make(map[string]bool)
+	for _, r := range references {
+		isReference[strings.TrimSpace(r.Bibkey+" "+r.Location.Raw)] = true
+	}
+	var seenReference bool
+	for _, c := range contexts {
+		if isReference[c] {
+			seenReference = true
+		} else if seenReference {
+			return true
+		}
+	}
+	return false
+}
+
+// splitList splits a comma separated list and reports whether it contained empty entries, e.g. by a trailing comma.
+func splitList(s string) ([]string, bool) {
+	if s == "" {
+		return nil, false
+	}
+	var list []string
+	var hasEmpty bool
+	for _, e := range strings.Split(s, ",") {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			hasEmpty = true
+			continue
+		}
+		list = append(list, e)
+	}
+	return list, hasEmpty
+}
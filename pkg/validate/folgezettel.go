@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"strings"
+	"time"
+)
+
+// validateFolgezettel checks whether the links between zettel and their predecessor form a tree:
+// no zettel links to itself, there are no cycles and a predecessor is not newer than its Folgezettel.
+// Every inconsistency names the files involved, so it can be fixed.
+func validateFolgezettel(zettel []zet.Zettel) []zet.InconErr {
+	var incons []zet.InconErr
+
+	m := make(map[string]zet.Zettel)
+	for _, z := range zettel {
+		if _, ok := m[z.Id]; !ok {
+			m[z.Id] = z
+		}
+	}
+
+	for _, z := range zettel {
+		if z.Predecessor == "" {
+			continue
+		}
+		if z.Predecessor == z.Id {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: zettel %v links to itself in %q", z.Id, z.Name),
+				Rule:     zet.SelfLinkRule,
+				Severity: zet.ErrorSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+			continue
+		}
+		if p, ok := m[z.Predecessor]; ok && isNewer(p.Id, z.Id) {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: predecessor %v is newer than zettel %v in %q", p.Id, z.Id, z.Name),
+				Rule:     zet.NewerPredecessorRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+		}
+	}
+
+	for _, cycle := range getCycles(zettel, m) {
+		var names, files []string
+		for _, id := range cycle {
+			names = append(names, fmt.Sprintf("%q", m[id].Name))
+			files = append(files, zettelFile(m[id]))
+		}
+		path := strings.Join(append(cycle, cycle[0]), " -> ")
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("zettel: cycle of predecessors %v in %v", path, strings.Join(names, ", ")),
+			Rule:     zet.CycleRule,
+			Severity: zet.ErrorSeverity,
+			Files:    files,
+			Id:       cycle[0],
+		})
+	}
+
+	return incons
+}
+
+// getCycles returns every cycle formed by the predecessors as a list of ids, starting with the smallest id.
+// Zettel linking to themselves are left out.
+func getCycles(zettel []zet.Zettel, m map[string]zet.Zettel) [][]string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int)
+
+	var cycles [][]string
+	for _, z := range zettel {
+		// Since every zettel has at most one predecessor, following them either ends or runs into a cycle.
+		var path []string
+		id := z.Id
+		for {
+			if state[id] == done {
+				break
+			}
+			if state[id] == visiting {
+				cycle := path[indexOf(path, id):]
+				if len(cycle) > 1 {
+					cycles = append(cycles, rotate(cycle))
+				}
+				break
+			}
+			state[id] = visiting
+			path = append(path, id)
+			next, ok := m[id]
+			if !ok || next.Predecessor == "" {
+				break
+			}
+			id = next.Predecessor
+			if _, ok := m[id]; !ok {
+				break
+			}
+		}
+		for _, p := range path {
+			state[p] = done
+		}
+	}
+	return cycles
+}
+
+// rotate returns the cycle starting with its smallest id, so the same cycle is always reported in the same way.
+func rotate(cycle []string) []string {
+	first := 0
+	for i, id := range cycle {
+		if id < cycle[first] {
+			first = i
+		}
+	}
+	return append(append([]string{}, cycle[first:]...), cycle[:first]...)
+}
+
+func indexOf(s []string, e string) int {
+	for i, a := range s {
+		if a == e {
+			return i
+		}
+	}
+	return -1
+}
+
+// isNewer checks if the date of id a is after the date of id b. The dates are compared as calendar dates,
+// so 000105a from the year 2000 is newer than 991231a from 1999. Ids without a valid date are not compared.
+func isNewer(a, b string) bool {
+	dateA, errA := time.Parse("060102", getDate(a))
+	dateB, errB := time.Parse("060102", getDate(b))
+	if errA != nil || errB != nil {
+		return false
+	}
+	return dateA.After(dateB)
+}
+
+// getDate returns the date of an id in the form YYMMDD, e.g. "170224" for "170224a".
+func getDate(id string) string {
+	if len(id) < 6 {
+		return id
+	}
+	return id[:6]
+}
@@ -0,0 +1,116 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ContentReader returns the content of a zettel file.
+type ContentReader interface {
+	GetZettelContent(name string) (string, error)
+}
+
+// HeaderParser parses the header of a zettel, which is written into text zettel when importing them.
+type HeaderParser interface {
+	HasHeader(content string) bool
+	Header(content string) (zet.Zettel, time.Time, error)
+}
+
+// validateHeaders checks that text zettel with a header, as written when importing them, still have
+// the same keywords, date, contexts, references and predecessor in the header as in the filename.
+// Zettel without a header are skipped, a header that can't be parsed anymore, e.g. after editing its date
+// by hand, is reported.
+func validateHeaders(zettel []zet.Zettel, r ContentReader, p HeaderParser) ([]zet.InconErr, error) {
+	var incons []zet.InconErr
+	for _, z := range zettel {
+		if strings.ToLower(filepath.Ext(z.Name)) != ".txt" {
+			continue
+		}
+		content, err := r.GetZettelContent(z.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !p.HasHeader(content) {
+			continue
+		}
+		h, date, err := p.Header(content)
+		if err != nil {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: header of %q could not be parsed: %v", z.Name, err),
+				Rule:     zet.HeaderMismatchRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+			continue
+		}
+
+		diffs := diffHeader(z, h, date)
+		if len(diffs) == 0 {
+			continue
+		}
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("zettel: header and filename of %q differ in %v", z.Name, strings.Join(diffs, ", ")),
+			Rule:     zet.HeaderMismatchRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{zettelFile(z)},
+			Id:       z.Id,
+		})
+	}
+	return incons, nil
+}
+
+// diffHeader returns the differences between the zettel parsed from the filename and the header.
+// The order of keywords, contexts and references doesn't matter.
+func diffHeader(z, h zet.Zettel, date time.Time) []string {
+	var diffs []string
+	add := func(field string, header, filename []string) {
+		if strings.Join(sorted(header), ", ") != strings.Join(sorted(filename), ", ") {
+			diffs = append(diffs, fmt.Sprintf("the %v %q (header) and %q (filename)", field, strings.Join(header, ", "), strings.Join(filename, ", ")))
+		}
+	}
+
+	add("keywords", nonEmpty(h.Keywords), nonEmpty(z.Keywords))
+	if d := date.Format("060102"); d != getDate(z.Id) {
+		diffs = append(diffs, fmt.Sprintf("the date %v (header) and the id %v (filename)", d, z.Id))
+	}
+	add("contexts", nonEmpty(h.Context), nonEmpty(z.Context))
+	add("references", references(h), references(z))
+	if h.Predecessor != z.Predecessor {
+		diffs = append(diffs, fmt.Sprintf("the predecessor %q (header) and %q (filename)", h.Predecessor, z.Predecessor))
+	}
+	return diffs
+}
+
+// references returns the references of a zettel in the form "bibkey location", e.g. "welter2011 12".
+func references(z zet.Zettel) []string {
+	var refs []string
+	for _, r := range z.References {
+		ref := r.Bibkey
+		if r.Location.Raw != "" {
+			ref += " " + r.Location.Raw
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func nonEmpty(s []string) []string {
+	var result []string
+	for _, e := range s {
+		if e = strings.TrimSpace(e); e != "" {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func sorted(s []string) []string {
+	c := append([]string{}, s...)
+	sort.Strings(c)
+	return c
+}
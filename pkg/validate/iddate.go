@@ -0,0 +1,56 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"time"
+)
+
+// validateIdDates checks that the date YYMMDD of every id is a real calendar date, is not in the future
+// compared to now and is not before the start year of your config.
+// Otherwise the zettel don't appear in chronological order, when searching your zettel by date.
+//
+// The order of the letters of ids within a day is not checked: the letters are taken from the keywords of
+// the zettel, so they don't follow the order of creation, and 'zet import' keeps no log of the order in which
+// zettel were imported, that they could be checked against.
+func validateIdDates(zettel []zet.Zettel, config zet.Config, now time.Time) []zet.InconErr {
+	var incons []zet.InconErr
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	for _, z := range zettel {
+		if len(z.Id) < 6 {
+			continue
+		}
+		date, err := time.Parse("060102", getDate(z.Id))
+		if err != nil {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: id %v has no valid date YYMMDD in %q", z.Id, z.Name),
+				Rule:     zet.InvalidIdDateRule,
+				Severity: zet.ErrorSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+			continue
+		}
+		if date.After(today) {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: id %v is dated in the future, on %v, in %q", z.Id, date.Format("2006-01-02"), z.Name),
+				Rule:     zet.FutureIdDateRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+		}
+		if config.StartYear > 0 && date.Year() < config.StartYear {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("zettel: id %v is dated before the start year %d of your zettelkasten in %q", z.Id, config.StartYear, z.Name),
+				Rule:     zet.EarlyIdDateRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{zettelFile(z)},
+				Id:       z.Id,
+			})
+		}
+	}
+
+	return incons
+}
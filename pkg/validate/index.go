@@ -0,0 +1,143 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"sort"
+	"strings"
+)
+
+// validateIndex checks whether your index is still a useful access point into your zettelkasten,
+// following the practice of Luhmann:
+//   - a topic has only a few ids (config.MaxIdsPerTopic),
+//   - an id is the start of a chain or a branch point, not a zettel deep inside a chain,
+//   - an id is not listed under many topics (config.MaxTopicsPerId) and
+//   - two topics don't lead to exactly the same zettel.
+//
+// Duplicate topics are already reported while parsing the index.
+func validateIndex(zettel []zet.Zettel, index zet.Index, config zet.Config) []zet.InconErr {
+	var incons []zet.InconErr
+
+	m := make(map[string]zet.Zettel)
+	for _, z := range zettel {
+		m[z.Id] = z
+	}
+
+	topics := make(map[string][]string) // topics[id] lists the topics of an id
+	for _, e := range index {
+		if config.MaxIdsPerTopic > 0 && len(e.Ids) > config.MaxIdsPerTopic {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("index: topic %q has %d ids, more than %d", e.Topic, len(e.Ids), config.MaxIdsPerTopic),
+				Rule:     zet.IndexTopicSizeRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{indexFile},
+			})
+		}
+		for _, id := range e.Ids {
+			topics[id] = append(topics[id], e.Topic)
+			if start, ok := getEntryPoint(id, m); ok && start != id {
+				incons = append(incons, zet.InconErr{
+					Message:  fmt.Errorf("index: id %v of topic %q lies inside a chain, use the start of the chain or a branch point like %v", id, e.Topic, start),
+					Rule:     zet.IndexEntryPointRule,
+					Severity: zet.WarningSeverity,
+					Files:    []string{indexFile},
+					Id:       id,
+				})
+			}
+		}
+	}
+
+	for id, t := range topics {
+		if config.MaxTopicsPerId > 0 && len(t) > config.MaxTopicsPerId {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("index: id %v is listed under %d topics, more than %d: %v", id, len(t), config.MaxTopicsPerId, strings.Join(t, ", ")),
+				Rule:     zet.IndexIdSpreadRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{indexFile},
+				Id:       id,
+			})
+		}
+	}
+
+	for _, o := range getOverlappingTopics(index, m) {
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("index: topics %q and %q lead to the same zettel", o[0], o[1]),
+			Rule:     zet.IndexOverlapRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{indexFile},
+		})
+	}
+
+	return incons
+}
+
+// getEntryPoint returns the nearest zettel at or above the id in its chain, that is a good entry point:
+// the start of the chain, the start of a branch or a zettel branching into several Folgezettel.
+// It returns false, if there is no zettel with the id.
+func getEntryPoint(id string, m map[string]zet.Zettel) (string, bool) {
+	z, ok := m[id]
+	if !ok {
+		return "", false
+	}
+	visited := make(map[string]bool)
+	for !visited[z.Id] {
+		visited[z.Id] = true
+		if len(z.Folgezettel) > 1 {
+			return z.Id, true
+		}
+		p, ok := m[z.Predecessor]
+		if !ok || len(p.Folgezettel) > 1 {
+			return z.Id, true
+		}
+		z = p
+	}
+	// The chain is a cycle, which gets reported elsewhere.
+	return id, true
+}
+
+// getOverlappingTopics returns pairs of topics, whose chains of Folgezettel contain exactly the same zettel.
+func getOverlappingTopics(index zet.Index, m map[string]zet.Zettel) [][2]string {
+	chains := make(map[string][]string) // chains[ids of the chains] lists the topics leading to them
+	var keys []string
+	for _, e := range index {
+		if len(e.Ids) == 0 {
+			continue
+		}
+		key := strings.Join(getChain(e.Ids, m), ",")
+		if _, ok := chains[key]; !ok {
+			keys = append(keys, key)
+		}
+		chains[key] = append(chains[key], e.Topic)
+	}
+
+	var overlapping [][2]string
+	for _, key := range keys {
+		t := chains[key]
+		for i := 1; i < len(t); i++ {
+			overlapping = append(overlapping, [2]string{t[0], t[i]})
+		}
+	}
+	return overlapping
+}
+
+// getChain returns the sorted ids of all zettel reachable from the ids via Folgezettel, including the ids.
+func getChain(ids []string, m map[string]zet.Zettel) []string {
+	visited := make(map[string]bool)
+	var chain []string
+	var add func(id string)
+	add = func(id string) {
+		if visited[id] {
+			return
+		}
+		visited[id] = true
+		chain = append(chain, id)
+		for _, f := range m[id].Folgezettel {
+			add(f)
+		}
+	}
+	for _, id := range ids {
+		add(id)
+	}
+	sort.Strings(chain)
+	return chain
+}
@@ -0,0 +1,186 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"regexp"
+	"strings"
+)
+
+// requiredFields lists for every BibTeX and BibLaTeX entry type the fields it must have.
+// Alternatives are separated by '|', e.g. a book has either an author or an editor.
+var requiredFields = map[string][]string{
+	"article":       {"author", "title", "journal|journaltitle", "year|date"},
+	"book":          {"author|editor", "title", "publisher", "year|date"},
+	"booklet":       {"title"},
+	"inbook":        {"author|editor", "title", "chapter|pages", "publisher", "year|date"},
+	"incollection":  {"author", "title", "booktitle", "publisher", "year|date"},
+	"inproceedings": {"author", "title", "booktitle", "year|date"},
+	"conference":    {"author", "title", "booktitle", "year|date"},
+	"manual":        {"title"},
+	"mastersthesis": {"author", "title", "school|institution", "year|date"},
+	"phdthesis":     {"author", "title", "school|institution", "year|date"},
+	"thesis":        {"author", "title", "type", "school|institution", "year|date"},
+	"online":        {"title", "url"},
+	"proceedings":   {"title", "year|date"},
+	"techreport":    {"author", "title", "institution", "year|date"},
+	"report":        {"author", "title", "type", "institution", "year|date"},
+	"unpublished":   {"author", "title", "note"},
+}
+
+// validateReferences returns all inconsistencies regarding your literature references.
+// If there are no inconsistencies, it returns nil.
+func validateReferences(zettel []zet.Zettel, references []zet.BibEntry, config zet.Config) []zet.InconErr {
+	var incons []zet.InconErr
+
+	for _, bibkey := range getDuplicateBibkeys(references) {
+		files := getDefiningFiles(bibkey, references)
+		if len(files) > 1 {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("reference: bibkey %q defined in more than one file: %v", bibkey, strings.Join(files, ", ")),
+				Rule:     zet.DuplicateBibkeyRule,
+				Severity: zet.ErrorSeverity,
+				Files:    files,
+			})
+			continue
+		}
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("reference: bibkey %q defined more than once", bibkey),
+			Rule:     zet.DuplicateBibkeyRule,
+			Severity: zet.ErrorSeverity,
+			Files:    files,
+		})
+	}
+
+	for _, bibkey := range getUncitedBibkeys(zettel, references) {
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("reference: bibkey %q not cited by any zettel (to read / to process)", bibkey),
+			Rule:     zet.UncitedBibkeyRule,
+			Severity: zet.WarningSeverity,
+			Files:    getDefiningFiles(bibkey, references),
+		})
+	}
+
+	for _, r := range references {
+		for _, field := range getMissingFields(r) {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("reference: bibkey %q of type @%v misses required field %q", r.Key, r.Type, field),
+				Rule:     zet.MissingFieldRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{r.File},
+			})
+		}
+	}
+
+	pattern, err := regexp.Compile(config.BibkeyPattern)
+	if err == nil {
+		for _, z := range zettel {
+			incons = append(incons, validateBibkeyPattern(z, pattern, config.BibkeyPattern)...)
+		}
+	}
+
+	return incons
+}
+
+// bibkeyLike matches a context that looks like a bibkey, e.g. "Welter2011" or "Welter:2011 12".
+var bibkeyLike = regexp.MustCompile(`^[A-Za-z][^\s\d,]*\d{4}[a-z]?(\s|$)`)
+
+// validateBibkeyPattern checks that the bibkeys of a zettel match the pattern of your config.
+// Only bibkeys in the format AUTHORYEAR in lower case are read as references from a filename. Any other
+// bibkey is read as context, so contexts looking like a bibkey are checked as well and reported,
+// even if they match the pattern, since the zettel doesn't cite a reference then.
+func validateBibkeyPattern(z zet.Zettel, pattern *regexp.Regexp, raw string) []zet.InconErr {
+	var messages []error
+	for _, r := range z.References {
+		if !pattern.MatchString(r.Bibkey) {
+			messages = append(messages, fmt.Errorf("reference: bibkey %q in zettel %v does not match the pattern %q", r.Bibkey, z.Id, raw))
+		}
+	}
+	for _, c := range z.Context {
+		if !bibkeyLike.MatchString(c) {
+			continue
+		}
+		bibkey := strings.Fields(c)[0]
+		if !pattern.MatchString(bibkey) {
+			messages = append(messages, fmt.Errorf("reference: bibkey %q in zettel %v does not match the pattern %q", bibkey, z.Id, raw))
+			continue
+		}
+		messages = append(messages, fmt.Errorf("reference: bibkey %q in zettel %v is read as context, since it is not in the format AUTHORYEAR in lower case", bibkey, z.Id))
+	}
+
+	var incons []zet.InconErr
+	for _, m := range messages {
+		incons = append(incons, zet.InconErr{
+			Message:  m,
+			Rule:     zet.BibkeyPatternRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{zettelFile(z)},
+			Id:       z.Id,
+		})
+	}
+	return incons
+}
+
+// getDuplicateBibkeys returns all bibkeys that are defined more than once.
+// A duplicate bibkey exists only once in the return slice.
+func getDuplicateBibkeys(references []zet.BibEntry) []string {
+	var duplicates []string
+	m := make(map[string]int)
+	for _, r := range references {
+		m[r.Key]++
+		if m[r.Key] == 2 {
+			duplicates = append(duplicates, r.Key)
+		}
+	}
+	return duplicates
+}
+
+// getDefiningFiles returns all references files that define the bibkey, e.g. "references.bib" and
+// "bibliography/shared.bib".
+func getDefiningFiles(bibkey string, references []zet.BibEntry) []string {
+	var files []string
+	for _, r := range references {
+		if r.Key == bibkey {
+			files = append(files, r.File)
+		}
+	}
+	return removeDuplicates(files)
+}
+
+// getUncitedBibkeys returns all bibkeys that are not cited by any zettel.
+// These are references that you still have to read or process.
+func getUncitedBibkeys(zettel []zet.Zettel, references []zet.BibEntry) []string {
+	cited := make(map[string]bool)
+	for _, z := range zettel {
+		for _, r := range z.References {
+			cited[r.Bibkey] = true
+		}
+	}
+
+	var uncited []string
+	for _, r := range references {
+		if !cited[r.Key] {
+			uncited = append(uncited, r.Key)
+		}
+	}
+	return removeDuplicates(uncited)
+}
+
+// getMissingFields returns all required fields the entry misses, depending on its type.
+// Alternative fields are returned in the form "author|editor".
+func getMissingFields(r zet.BibEntry) []string {
+	var missing []string
+	for _, required := range requiredFields[r.Type] {
+		var found bool
+		for _, field := range strings.Split(required, "|") {
+			if strings.TrimSpace(r.Fields[field]) != "" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, required)
+		}
+	}
+	return missing
+}
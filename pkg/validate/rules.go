@@ -0,0 +1,58 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+)
+
+// applyRules applies the rules and suppressions of your config to the inconsistencies:
+// inconsistencies of rules that are off or suppressed for their id are left out,
+// the others get the severity set in your config.
+// Suppressions that don't match any inconsistency are reported, so they don't hide new ones later on.
+func applyRules(incons []zet.InconErr, config zet.Config) []zet.InconErr {
+	used := make([]bool, len(config.Suppressions))
+
+	var result []zet.InconErr
+	for _, i := range incons {
+		if s := suppressedBy(i, config.Suppressions); s != -1 {
+			used[s] = true
+			continue
+		}
+		result = append(result, i)
+	}
+
+	for n, s := range config.Suppressions {
+		if used[n] {
+			continue
+		}
+		result = append(result, zet.InconErr{
+			Message:  fmt.Errorf("config: suppression of rule %q for id %v is unused", s.Rule, s.Id),
+			Rule:     zet.UnusedSuppressionRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{configFile},
+			Id:       s.Id,
+		})
+	}
+
+	var configured []zet.InconErr
+	for _, i := range result {
+		if s, ok := config.Rules[i.Rule]; ok {
+			if s == zet.OffSeverity {
+				continue
+			}
+			i.Severity = s
+		}
+		configured = append(configured, i)
+	}
+	return configured
+}
+
+// suppressedBy returns the index of the suppression matching the inconsistency or -1, if there is none.
+func suppressedBy(i zet.InconErr, suppressions []zet.Suppression) int {
+	for n, s := range suppressions {
+		if s.Rule == i.Rule && s.Id == i.Id {
+			return n
+		}
+	}
+	return -1
+}
@@ -0,0 +1,84 @@
+package validate
+
+import (
+	"fmt"
+	"github.com/crelder/zet"
+	"sort"
+	"strings"
+)
+
+// SealReader returns the checksums recorded when sealing your zettelkasten and the current checksums
+// of your zettel files (map[filename]checksum).
+type SealReader interface {
+	GetSeal() (map[string]string, bool, error)
+	GetChecksums() (map[string]string, error)
+}
+
+// validateSeal checks the files in the folder 'zettel' against the checksums recorded by 'zet seal':
+// zettel are immutable, so a changed or missing file points to an accidental edit, bit rot or a sync tool
+// gone wrong. Files added without importing them are not sealed.
+// If your zettelkasten is not sealed, there is nothing to check.
+func validateSeal(r SealReader, p FilenameParser) ([]zet.InconErr, error) {
+	seal, sealed, err := r.GetSeal()
+	if err != nil || !sealed {
+		return nil, err
+	}
+	checksums, err := r.GetChecksums()
+	if err != nil {
+		return nil, err
+	}
+
+	var incons []zet.InconErr
+	for _, name := range sortedKeys(seal) {
+		c, ok := checksums[name]
+		switch {
+		case !ok:
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("seal: zettel %q is missing", name),
+				Rule:     zet.MissingZettelRule,
+				Severity: zet.ErrorSeverity,
+				Files:    []string{"zettel/" + name},
+				Id:       getId(name, p),
+			})
+		case c != seal[name]:
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("seal: content of zettel %q changed since sealing", name),
+				Rule:     zet.ChangedZettelRule,
+				Severity: zet.ErrorSeverity,
+				Files:    []string{"zettel/" + name},
+				Id:       getId(name, p),
+			})
+		}
+	}
+	for _, name := range sortedKeys(checksums) {
+		if _, ok := seal[name]; !ok {
+			incons = append(incons, zet.InconErr{
+				Message:  fmt.Errorf("seal: zettel %q is not sealed, import zettel or run 'zet seal' to accept it", name),
+				Rule:     zet.UnsealedZettelRule,
+				Severity: zet.WarningSeverity,
+				Files:    []string{"zettel/" + name},
+				Id:       getId(name, p),
+			})
+		}
+	}
+	return incons, nil
+}
+
+// getId returns the id at the beginning of a filename, e.g. "170224a" for "170224a - Go.txt",
+// or an empty string, if there is none.
+func getId(name string, p FilenameParser) string {
+	id := strings.SplitN(name, " ", 2)[0]
+	if !p.IsId(id) {
+		return ""
+	}
+	return id
+}
+
+func sortedKeys(m map[string]string) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -1,25 +1,44 @@
 package validate
 
 import (
-	"errors"
 	"fmt"
 	"github.com/crelder/zet"
 	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	indexFile  = "index.txt"
+	configFile = "config.txt"
 )
 
 // Validator analyzes any inconsistencies the zettelkasten has.
 // Validator satisfies the zet.Validator interface.
 type Validator struct {
-	Repo zet.Repo
+	Repo   zet.Repo
+	Reader ContentReader
+	Parser Parser
+	Seal   SealReader
+}
+
+// Parser parses the filenames and headers of zettel.
+type Parser interface {
+	FilenameParser
+	HeaderParser
 }
 
-func New(r zet.Repo) Validator {
+func New(r zet.Repo, c ContentReader, p Parser, s SealReader) Validator {
 	return Validator{
-		Repo: r,
+		Repo:   r,
+		Reader: c,
+		Parser: p,
+		Seal:   s,
 	}
 }
 
 // Val returns all inconsistencies that your zettelkasten has in form of a slice of inconsistencies.
+// The rules and suppressions of your config decide which inconsistencies are reported with which severity.
 // If the same inconsistency occurs several times, only one is returned, not several.
 // If there are none, it returns nil.
 func (v Validator) Val() ([]zet.InconErr, error) {
@@ -38,12 +57,47 @@ func (v Validator) Val() ([]zet.InconErr, error) {
 	}
 	incons = append(incons, i...)
 
-	bibkeys, err3 := v.Repo.GetBibkeys()
+	references, i, err3 := v.Repo.GetReferences()
 	if err3 != nil {
 		return nil, err3
 	}
+	incons = append(incons, i...)
+
+	var bibkeys []string
+	for _, r := range references {
+		bibkeys = append(bibkeys, r.Key)
+	}
+
+	config, i, err4 := v.Repo.GetConfig()
+	if err4 != nil {
+		return nil, err4
+	}
+	incons = append(incons, i...)
+
+	contexts, i, err5 := v.Repo.GetContexts()
+	if err5 != nil {
+		return nil, err5
+	}
+	incons = append(incons, i...)
 
 	incons = append(incons, validate(zettel, index, bibkeys)...)
+	incons = append(incons, validateReferences(zettel, references, config)...)
+	incons = append(incons, validateContexts(zettel, contexts)...)
+	incons = append(incons, validateIndex(zettel, index, config)...)
+	incons = append(incons, validateFolgezettel(zettel)...)
+	incons = append(incons, validateFilenames(zettel, v.Parser)...)
+	incons = append(incons, validateIdDates(zettel, config, time.Now())...)
+	headerIncons, err6 := validateHeaders(zettel, v.Reader, v.Parser)
+	if err6 != nil {
+		return nil, err6
+	}
+	incons = append(incons, headerIncons...)
+	sealIncons, err7 := validateSeal(v.Seal, v.Parser)
+	if err7 != nil {
+		return nil, err7
+	}
+	incons = append(incons, sealIncons...)
+	incons = applyRules(incons, config)
 	incons = makeUnique(incons)
 	sort.Slice(incons, func(i, j int) bool {
 		return incons[i].Error() < incons[j].Error()
@@ -52,16 +106,17 @@ func (v Validator) Val() ([]zet.InconErr, error) {
 	return incons, nil
 }
 
-// makeUnique returns a unique list of errors, where a specific error string only occurs once.
+// makeUnique returns a unique list of inconsistencies, where a specific error string only occurs once.
+// Of several inconsistencies with the same error string, the first one is kept.
 func makeUnique(incons []zet.InconErr) []zet.InconErr {
 	m := make(map[string]bool)
+	var result []zet.InconErr
 	for _, i := range incons {
+		if m[i.Error()] {
+			continue
+		}
 		m[i.Error()] = true
-	}
-
-	var result []zet.InconErr
-	for str := range m {
-		result = append(result, zet.InconErr{errors.New(str)})
+		result = append(result, i)
 	}
 	return result
 }
@@ -74,29 +129,106 @@ func validate(zettel []zet.Zettel, index zet.Index, bibkeys []string) []zet.Inco
 
 	doubleIds := getNonUniqueIds(zettel)
 	for _, doubleId := range doubleIds {
+		var files []string
+		for _, z := range zettel {
+			if z.Id == doubleId {
+				files = append(files, zettelFile(z))
+			}
+		}
 		incons = append(incons, zet.InconErr{
-			Message: fmt.Errorf("zettel: id %v not unique", doubleId)})
+			Message:  fmt.Errorf("zettel: id %v not unique", doubleId),
+			Rule:     zet.DuplicateIdRule,
+			Severity: zet.ErrorSeverity,
+			Files:    files,
+			Id:       doubleId,
+		})
 	}
 
 	deadLinks := getDeadLinks(zettel)
 	for _, deadLink := range deadLinks {
-		incons = append(incons, zet.InconErr{fmt.Errorf("zettel: link to id %v not existing", deadLink)})
+		var names, files []string
+		for _, z := range zettel {
+			if z.Predecessor == deadLink {
+				names = append(names, fmt.Sprintf("%q", z.Name))
+				files = append(files, zettelFile(z))
+			}
+		}
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("zettel: link to id %v not existing in %v", deadLink, strings.Join(names, ", ")),
+			Rule:     zet.DeadLinkRule,
+			Severity: zet.ErrorSeverity,
+			Files:    files,
+			Id:       deadLink,
+		})
 	}
 
 	deadIndexLinks := getDeadIndexLinks(zettel, index)
 	for _, deadIndexLink := range deadIndexLinks {
-		incons = append(incons, zet.InconErr{fmt.Errorf("index: link to id %v not existing", deadIndexLink)})
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("index: link to id %v not existing", deadIndexLink),
+			Rule:     zet.IndexDeadLinkRule,
+			Severity: zet.ErrorSeverity,
+			Files:    []string{indexFile},
+			Id:       deadIndexLink,
+		})
+	}
+
+	for _, e := range index {
+		for _, topic := range e.SeeAlso {
+			if _, ok := index.Ids(topic); !ok {
+				incons = append(incons, zet.InconErr{
+					Message:  fmt.Errorf("index: topic %q refers to unknown topic %q", e.Topic, topic),
+					Rule:     zet.IndexUnknownTopicRule,
+					Severity: zet.ErrorSeverity,
+					Files:    []string{indexFile},
+				})
+			}
+		}
 	}
 
 	// Missing Bibkey
 	missingBibKeys := getMissingBibKeys(zettel, bibkeys)
 	for _, missingBibKey := range missingBibKeys {
-		incons = append(incons, zet.InconErr{fmt.Errorf("reference: missing bibkey %q", missingBibKey)})
+		var files []string
+		for _, z := range zettel {
+			for _, r := range z.References {
+				if r.Bibkey == missingBibKey {
+					files = append(files, zettelFile(z))
+					break
+				}
+			}
+		}
+		incons = append(incons, zet.InconErr{
+			Message:  fmt.Errorf("reference: missing bibkey %q", missingBibKey),
+			Rule:     zet.MissingBibkeyRule,
+			Severity: zet.ErrorSeverity,
+			Files:    removeDuplicates(files),
+		})
+	}
+
+	// Locations within a reference that could not be parsed
+	for _, z := range zettel {
+		for _, r := range z.References {
+			if r.Location.Kind == zet.UnknownLocation {
+				incons = append(incons, zet.InconErr{
+					Message:  fmt.Errorf("reference: could not parse location %q of bibkey %q in zettel %v", r.Location.Raw, r.Bibkey, z.Id),
+					Rule:     zet.UnknownLocationRule,
+					Severity: zet.WarningSeverity,
+					Files:    []string{zettelFile(z)},
+					Id:       z.Id,
+				})
+			}
+		}
 	}
 
 	return incons
 }
 
+// zettelFile returns the path of the file of a zettel relative to your zettelkasten, e.g. "zettel/170224a - Go.txt".
+func zettelFile(z zet.Zettel) string {
+	return "zettel/" + z.Name
+}
+
 // getNonUniqueIds returns all ids that exist more than once in the zettelkasten.
 // A double id exists only once in the return string.
 func getNonUniqueIds(zettels []zet.Zettel) []string {
@@ -172,8 +304,8 @@ func idExist(id string, zettel []zet.Zettel) bool {
 
 func getDeadIndexLinks(zettel []zet.Zettel, index zet.Index) []string {
 	var deadLinks []string
-	for _, ids := range index {
-		for _, id := range ids {
+	for _, e := range index {
+		for _, id := range e.Ids {
 			if !idExist(id, zettel) {
 				deadLinks = append(deadLinks, id)
 			}
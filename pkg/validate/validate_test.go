@@ -1,11 +1,15 @@
 package validate
 
 import (
+	"errors"
+	"github.com/crelder/zet"
 	"github.com/crelder/zet/pkg/parse"
 	"github.com/crelder/zet/pkg/transport/fs"
 	"github.com/google/go-cmp/cmp"
 	"os"
+	"sort"
 	"testing"
+	"time"
 )
 
 func TestValidate(t *testing.T) {
@@ -17,7 +21,7 @@ func TestValidate(t *testing.T) {
 	var pathTestRepo = wd + "/testdata/zettelkasten"
 	parser := parse.New()
 	repo := fs.New(pathTestRepo, parser)
-	validator := New(repo)
+	validator := New(repo, repo, parser, repo)
 
 	// Act
 	inconsErrs, err2 := validator.Val()
@@ -32,16 +36,353 @@ func TestValidate(t *testing.T) {
 	}
 
 	want := map[string]bool{
-		"zettel: link to id 160122e not existing": true,
-		"zettel: id 180112a not unique":           true,
+		"zettel: link to id 160122e not existing in \"190604b - dead link - 160122e.txt\"":                                                                                                  true,
+		"zettel: cycle of predecessors 210101a -> 210102b -> 210103c -> 210101a in \"210101a - Cycle - 210102b.txt\", \"210102b - Cycle - 210103c.txt\", \"210103c - Cycle - 210101a.txt\"": true,
+		"zettel: predecessor 210102b is newer than zettel 210101a in \"210101a - Cycle - 210102b.txt\"":                                                                                     true,
+		"zettel: predecessor 210103c is newer than zettel 210102b in \"210102b - Cycle - 210103c.txt\"":                                                                                     true,
+		"zettel: predecessor 210106f is newer than zettel 210105e in \"210105e - Newer predecessor - 210106f.txt\"":                                                                         true,
+		"context: unknown context \"210106f\" in zettel 210107g":                                                                                                                            true,
+		"filename: \"180112a - not-unique-id.txt\" has the lower-case keyword \"not-unique-id\", rename it to \"180112a - Not-unique-id.txt\"":                                              true,
+		"filename: \"190604b - dead link - 160122e.txt\" has the lower-case keyword \"dead link\", rename it to \"190604b - Dead link - 160122e.txt\"":                                      true,
+		"filename: \"210107g -  Double  space, trailing comma, - Marco Fitz, 210106f.txt\" has superfluous spaces, a trailing or empty ',', the lower-case keyword \"trailing comma\", the predecessor 210106f in the references/context part, rename it to \"210107g - Double space, Trailing comma - Marco Fitz - 210106f.txt\"": true,
+		"zettel: zettel 210104d links to itself in \"210104d - Self link - 210104d.txt\"": true,
+		"zettel: id 180112a not unique": true,
+		"zettel: header of \"210109i - Broken header.txt\" could not be parsed: parseDate: could not parse date": true,
+		"zettel: header and filename of \"210108h - Header drift - GopherCon, pike1989 12 - 210106f.txt\" differ in the keywords \"Header drift, Edited\" (header) and \"Header drift\" (filename), the date 210109 (header) and the id 210108h (filename), the references \"pike1989 13\" (header) and \"pike1989 12\" (filename), the predecessor \"\" (header) and \"210106f\" (filename)": true,
 		"parse filename: more than one predecessor for file \"170327f - More than one predecessor - 180112a, 170311f\"": true,
 		"parse filename: could not parse id from filename \"noId.txt\"":                                                 true,
-		"index: could not parse line \"Water::170312w\"":                                                                true,
+		"index: line 2: could not parse \"Water::170312w\", quote topics containing ':'":                                true,
+		"index: topic \"Heat\" refers to unknown topic \"Thermodynamics\"":                                              true,
 		"index: link to id 180317q not existing":                                                                        true,
 		"reference: missing bibkey \"knut2012\"":                                                                        true,
+		"reference: could not parse location \"p3\" of bibkey \"pike1989\" in zettel 200125u":                           true,
+		"context: unknown context \"Marko Fitz\" in zettel 200128m, did you mean \"Marco Fitz\"?":                       true,
+		"context: unknown context \"Bob\" in zettel 200128m":                                                            true,
+		"reference: bibkey \"knuth1997\" defined in more than one file: references.bib, bibliography/shared.bib":        true,
+		"reference: bibkey \"knuth1997b\" in zettel 200127k does not match the pattern \"^[a-z]+\\\\d{4}$\"":            true,
+		"reference: bibkey \"pike1989\" defined more than once":                                                         true,
+		"reference: bibkey \"knuth1997\" not cited by any zettel (to read / to process)":                                true,
+		"reference: bibkey \"pike1989\" of type @article misses required field \"journal|journaltitle\"":                true,
 	}
 
 	if diff := cmp.Diff(got, want); diff != "" {
 		t.Errorf(diff)
 	}
 }
+
+func TestValidateBibkeyPattern(t *testing.T) {
+	zettel := []zet.Zettel{
+		{
+			Id:         "170224a",
+			Name:       "170224a - Entropie - Marco Fitz, GopherCon, Welter2012 12, Welter:2011, welter2011, shannon1948c.txt",
+			Context:    []string{"Marco Fitz", "GopherCon", "Welter2012 12", "Welter:2011"},
+			References: []zet.Reference{{Bibkey: "welter2011"}, {Bibkey: "shannon1948c"}},
+		},
+	}
+	config := zet.Config{BibkeyPattern: `^[A-Za-z]+\d{4}$`}
+
+	want := []string{
+		`reference: bibkey "Welter2012" in zettel 170224a is read as context, since it is not in the format AUTHORYEAR in lower case`,
+		`reference: bibkey "Welter:2011" in zettel 170224a does not match the pattern "^[A-Za-z]+\\d{4}$"`,
+		`reference: bibkey "shannon1948c" in zettel 170224a does not match the pattern "^[A-Za-z]+\\d{4}$"`,
+	}
+
+	var got []string
+	for _, e := range validateReferences(zettel, nil, config) {
+		got = append(got, e.Error())
+	}
+	sort.Strings(got)
+	sort.Strings(want)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestValidateIndex(t *testing.T) {
+	// 170101a is the start of the chain 170101a -> 170102b -> 170103c, which branches into 170104d and 170105e.
+	zettel := []zet.Zettel{
+		{Id: "170101a", Folgezettel: []string{"170102b"}},
+		{Id: "170102b", Predecessor: "170101a", Folgezettel: []string{"170103c"}},
+		{Id: "170103c", Predecessor: "170102b", Folgezettel: []string{"170104d", "170105e"}},
+		{Id: "170104d", Predecessor: "170103c"},
+		{Id: "170105e", Predecessor: "170103c", Folgezettel: []string{"170106f"}},
+		{Id: "170106f", Predecessor: "170105e"},
+		{Id: "170107g"},
+	}
+	index := zet.Index{
+		{Topic: "Start", Ids: []string{"170101a"}},
+		{Topic: "Branches", Ids: []string{"170103c", "170104d", "170105e"}},
+		{Topic: "Inside", Ids: []string{"170102b", "170106f"}},
+		{Topic: "Many", Ids: []string{"170101a", "170107g"}},
+		{Topic: "Beginning", Ids: []string{"170101a"}},
+		{Topic: "Synonym", SeeAlso: []string{"Start"}},
+	}
+	config := zet.Config{MaxIdsPerTopic: 2, MaxTopicsPerId: 2}
+
+	want := []string{
+		`index: id 170101a is listed under 3 topics, more than 2: Start, Many, Beginning`,
+		`index: id 170102b of topic "Inside" lies inside a chain, use the start of the chain or a branch point like 170101a`,
+		`index: id 170106f of topic "Inside" lies inside a chain, use the start of the chain or a branch point like 170105e`,
+		`index: topic "Branches" has 3 ids, more than 2`,
+		`index: topics "Start" and "Beginning" lead to the same zettel`,
+	}
+
+	var got []string
+	for _, e := range validateIndex(zettel, index, config) {
+		got = append(got, e.Error())
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestApplyRules(t *testing.T) {
+	incons := []zet.InconErr{
+		{Message: errors.New("old scan"), Rule: zet.FilenameFormatRule, Severity: zet.WarningSeverity, Id: "170101a"},
+		{Message: errors.New("new zettel"), Rule: zet.FilenameFormatRule, Severity: zet.WarningSeverity, Id: "170102b"},
+		{Message: errors.New("overlap"), Rule: zet.IndexOverlapRule, Severity: zet.WarningSeverity},
+		{Message: errors.New("uncited"), Rule: zet.UncitedBibkeyRule, Severity: zet.WarningSeverity},
+		{Message: errors.New("dead link"), Rule: zet.DeadLinkRule, Severity: zet.ErrorSeverity, Id: "170103c"},
+	}
+	config := zet.Config{
+		Rules: map[string]zet.Severity{zet.IndexOverlapRule: zet.OffSeverity, zet.UncitedBibkeyRule: zet.ErrorSeverity},
+		Suppressions: []zet.Suppression{
+			{Rule: zet.FilenameFormatRule, Id: "170101a", Reason: "old scan without keywords"},
+			{Rule: zet.CycleRule, Id: "170103c", Reason: "fixed long ago"},
+		},
+	}
+
+	want := []string{
+		"warning: new zettel",
+		"error: uncited",
+		"error: dead link",
+		`warning: config: suppression of rule "cycle" for id 170103c is unused`,
+	}
+
+	var got []string
+	for _, i := range applyRules(incons, config) {
+		got = append(got, string(i.Severity)+": "+i.Error())
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestValidateSuppressions(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	if err := os.Mkdir(dir+"/zettel", 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"zettel/170224a - old scan.png":            "",
+		"zettel/170225b - Self link - 170225b.txt": "",
+		"zettel/170226c - Citing - knut2012.txt":   "",
+		"index.txt":                                "Go: 170224a, 170225b, 170226c\n",
+		"references.bib":                           "",
+		"config.txt":                               "[suppress]\nfilename-format 170224a = old scan\nself-link 170225b = kept as example\ncycle 170226c = no longer needed\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(dir+"/"+name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	parser := parse.New()
+	repo := fs.New(dir, parser)
+	validator := New(repo, repo, parser, repo)
+
+	// Act
+	incons, err := validator.Val()
+	if err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+
+	// Assert
+	// The suppressed inconsistencies are left out, the unused suppression is reported.
+	var got []string
+	for _, i := range incons {
+		got = append(got, i.Error())
+	}
+	sort.Strings(got)
+	want := []string{
+		`config: suppression of rule "cycle" for id 170226c is unused`,
+		`reference: missing bibkey "knut2012"`,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestValidateIdDates(t *testing.T) {
+	zettel := []zet.Zettel{
+		{Id: "170224a", Name: "170224a - Valid.txt"},
+		{Id: "171345a", Name: "171345a - Month 13.txt"},
+		{Id: "170229b", Name: "170229b - No leap year.txt"},
+		{Id: "200229c", Name: "200229c - Leap year.txt"},
+		{Id: "261020d", Name: "261020d - Tomorrow.txt"},
+		{Id: "141231e", Name: "141231e - Before start.txt"},
+	}
+	config := zet.Config{StartYear: 2015}
+	now := time.Date(2026, 10, 19, 23, 0, 0, 0, time.UTC)
+
+	want := []string{
+		`zettel: id 141231e is dated before the start year 2015 of your zettelkasten in "141231e - Before start.txt"`,
+		`zettel: id 170229b has no valid date YYMMDD in "170229b - No leap year.txt"`,
+		`zettel: id 171345a has no valid date YYMMDD in "171345a - Month 13.txt"`,
+		`zettel: id 261020d is dated in the future, on 2026-10-20, in "261020d - Tomorrow.txt"`,
+	}
+
+	var got []string
+	for _, e := range validateIdDates(zettel, config, now) {
+		got = append(got, e.Error())
+	}
+	sort.Strings(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestValidateFolgezettelDates(t *testing.T) {
+	zettel := []zet.Zettel{
+		{Id: "991231a", Name: "991231a - Last century.txt"},
+		{Id: "000105a", Name: "000105a - Next century - 991231a.txt", Predecessor: "991231a"},
+		{Id: "000106b", Name: "000106b - Predecessor from the future - 000107c.txt", Predecessor: "000107c"},
+		{Id: "000107c", Name: "000107c - Newer.txt"},
+	}
+
+	want := []string{
+		`zettel: predecessor 000107c is newer than zettel 000106b in "000106b - Predecessor from the future - 000107c.txt"`,
+	}
+
+	var got []string
+	for _, e := range validateFolgezettel(zettel) {
+		got = append(got, e.Error())
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf(diff)
+	}
+}
+
+func TestLintFilename(t *testing.T) {
+	tcs := []struct {
+		name      string
+		corrected string
+		problems  []string
+	}{
+		{
+			name:      "170224a - Polymorphismus, Schnittstelle - Marco Fitz, clausen2021 87 - 170223b.txt",
+			corrected: "170224a - Polymorphismus, Schnittstelle - Marco Fitz, clausen2021 87 - 170223b.txt",
+		},
+		{
+			name:      "170224a - Polymorphismus - clausen2021 87, Marco Fitz, kernighan2016 - 170223b.txt",
+			corrected: "170224a - Polymorphismus - Marco Fitz, clausen2021 87, kernighan2016 - 170223b.txt",
+			problems:  []string{"references before contexts"},
+		},
+		{
+			name:      "170224a - 170223b.png",
+			corrected: "170224a - 170223b.png",
+		},
+		{
+			name:      "170224a -  Polymorphismus,  Schnittstelle .txt",
+			corrected: "170224a - Polymorphismus, Schnittstelle.txt",
+			problems:  []string{"superfluous spaces"},
+		},
+		{
+			name:      "170224a - Polymorphismus, - clausen2021 87,.txt",
+			corrected: "170224a - Polymorphismus - clausen2021 87.txt",
+			problems:  []string{"a trailing or empty ','"},
+		},
+		{
+			name:      "170224a - Polymorphismus - Marco Fitz, 170223b.txt",
+			corrected: "170224a - Polymorphismus - Marco Fitz - 170223b.txt",
+			problems:  []string{"the predecessor 170223b in the references/context part"},
+		},
+		{
+			name:      "170224a - polymorphismus, Schnittstelle.PDF",
+			corrected: "170224a - Polymorphismus, Schnittstelle.pdf",
+			problems:  []string{"an upper-case extension", "the lower-case keyword \"polymorphismus\""},
+		},
+		{
+			name:      "170224a Polymorphismus.txt",
+			corrected: "170224a - Polymorphismus.txt",
+			problems:  []string{"no ' - ' after the id"},
+		},
+		{
+			name:      "170224a - Polymorphismus - Marco Fitz - Marco.md",
+			corrected: "170224a - Polymorphismus - Marco Fitz - Marco.md",
+			problems:  []string{"the extension \".md\", which is not one of .txt, .png or .pdf", "\"Marco\" in the place of the predecessor id"},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			corrected, problems := lintFilename(tc.name, parse.New())
+			if corrected != tc.corrected {
+				t.Errorf("Got %q, wanted %q", corrected, tc.corrected)
+			}
+			if diff := cmp.Diff(tc.problems, problems); diff != "" {
+				t.Errorf(diff)
+			}
+		})
+	}
+}
+
+func TestValidateDetails(t *testing.T) {
+	// Arrange
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Errorf("could not get the current working dir")
+	}
+	parser := parse.New()
+	repo := fs.New(wd+"/testdata/zettelkasten", parser)
+	validator := New(repo, repo, parser, repo)
+
+	// Act
+	inconsErrs, err := validator.Val()
+	if err != nil {
+		t.Errorf("Err: %v", err)
+	}
+
+	// Assert
+	// Every inconsistency has a rule and a severity.
+	got := make(map[string]zet.InconErr)
+	for _, i := range inconsErrs {
+		if i.Rule == "" || (i.Severity != zet.ErrorSeverity && i.Severity != zet.WarningSeverity) {
+			t.Errorf("inconsistency %q has no rule or severity", i)
+		}
+		got[i.Error()] = i
+	}
+
+	want := []zet.InconErr{
+		{
+			Message:  errors.New("zettel: id 180112a not unique"),
+			Rule:     zet.DuplicateIdRule,
+			Severity: zet.ErrorSeverity,
+			Files:    []string{"zettel/180112a - Not unique Id.txt", "zettel/180112a - not-unique-id.txt"},
+			Id:       "180112a",
+		},
+		{
+			Message:  errors.New(`index: line 2: could not parse "Water::170312w", quote topics containing ':'`),
+			Rule:     zet.IndexSyntaxRule,
+			Severity: zet.ErrorSeverity,
+			Files:    []string{"index.txt"},
+		},
+		{
+			Message:  errors.New(`reference: bibkey "knuth1997" not cited by any zettel (to read / to process)`),
+			Rule:     zet.UncitedBibkeyRule,
+			Severity: zet.WarningSeverity,
+			Files:    []string{"references.bib", "bibliography/shared.bib"},
+		},
+	}
+	for _, w := range want {
+		g, ok := got[w.Error()]
+		if !ok {
+			t.Errorf("missing inconsistency %q", w)
+			continue
+		}
+		if diff := cmp.Diff(w, g, cmp.Comparer(func(a, b error) bool { return a.Error() == b.Error() })); diff != "" {
+			t.Errorf(diff)
+		}
+	}
+}
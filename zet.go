@@ -15,8 +15,20 @@ package zet
 //
 // Import takes one or more zettel contents and persists each content.
 // In case of an error it returns the number of zettel contents already persisted until the occurrence of the error.
+//
+// ImportAnnotations takes a CSV or JSON export of annotations (e.g. from Zotero or Readwise)
+// and persists each annotation as a zettel.
+//
+// ImportScans takes scans (.png or .pdf) with their metadata in a sidecar textfile or
+// a manifest and persists each scan as a zettel.
+//
+// ImportReferences takes a RIS or EndNote XML export of literature references and
+// adds each reference with a newly generated bibkey to your references.bib.
 type Importer interface {
 	Import(path string) (int, error)
+	ImportAnnotations(path string) (int, error)
+	ImportScans(path string) (int, error)
+	ImportReferences(path string) (int, error)
 }
 
 // Initiator supports starting with this personal knowledge management system.
@@ -33,8 +45,42 @@ type Initiator interface {
 // entry points into your zettelkasten (so-called "views").
 //
 // CreateViews will create all access points into your zettelkasten.
+//
+// ExportReferences will export the literature references cited by a selection of zettel
+// (all zettel, a chain starting at an id or a topic of the index) as BibTeX and CSL-JSON.
 type Exporter interface {
 	Export() error
+	ExportReferences(selection string) error
+}
+
+// Formatter renames the files of your zettel to their canonical filename.
+//
+// Format returns the renames, with check it doesn't rename anything.
+type Formatter interface {
+	Format(check bool) ([]string, error)
+}
+
+// Sealer protects your zettel, which are immutable, against unnoticed changes.
+//
+// Seal records the checksum of every file in the folder 'zettel' and returns the number of files.
+// Afterwards, Validator reports files that changed, disappeared or appeared without being imported.
+type Sealer interface {
+	Seal() (int, error)
+}
+
+// Backuper makes backups of your zettelkasten, which can be verified and restored.
+//
+// Backup writes a compressed archive of your zettelkasten with the checksums of all its files into dest
+// and returns the filename of the archive. Generated folders like 'INDEX' or 'EXPORT' are left out.
+//
+// Verify checks the files of an archive against its checksums and returns the number of files.
+//
+// Restore extracts an archive into an empty folder and checks the restored files against the checksums.
+// It returns the number of files restored.
+type Backuper interface {
+	Backup(dest string) (string, error)
+	Verify(archive string) (int, error)
+	Restore(archive, dest string) (int, error)
 }
 
 // Validator is the instance for accessing all functionality regarding
@@ -44,7 +90,18 @@ type Exporter interface {
 // Inconsistencies can be:
 //   - dead links
 //   - double ids
+//   - cycles, self-links and predecessors newer than their Folgezettel
+//   - filenames not following the format
 //   - missing reference entry
+//   - duplicate, uncited or incomplete reference entries
+//   - locations within a reference that can not be parsed
+//   - unknown or misspelled contexts, if you declared your contexts
+//   - index topics referring to unknown topics
+//   - index topics with too many ids, ids inside a chain, ids under too many topics and overlapping topics
+//   - ids without a valid date and headers of text zettel differing from their filename
+//   - zettel files changed, missing or added since sealing your zettelkasten
+//
+// Every inconsistency has a rule and a severity, errors or warnings.
 //
 // The second return parameter contains a potential error.
 type Validator interface {
@@ -58,19 +115,36 @@ type Validator interface {
 // The structure is always the same since the raw filenames get sorted by name before processing so that
 // the order of links, etc. is always the same; therefore, the VIEWS structure also stays the same.
 //
-// GetIndex returns a Index that maps thematic entry topics to one or more ids.
+// GetIndex returns a Index that maps thematic entry topics to one or more ids in the order of your index.txt.
 //
 // GetBibkeys returns a list of bibkeys representing literature references.
 //
+// GetReferences returns all entries of your references.bib and all errors that occurred while parsing them.
+// Besides references.bib, or instead of it, the folder 'bibliography' can hold further .bib files; their
+// entries get merged.
+//
+// GetConfig returns the settings of your zettelkasten and all errors that occurred while parsing them.
+// If there is no config file, it returns the default settings.
+//
+// GetContexts returns the contexts declared in your registry of contexts and all errors that occurred while
+// parsing them. If there is no registry, it returns no contexts.
+//
 // Save takes a map[filename]content of zettel and saves these.
-// filename is the name of the file that holds the thought. Content is the text content of your thought.
+// filename is the name of the file that holds the thought. Content is the text content of your thought
+// or the raw data of a scan.
 // In case of success it returns a nil error and the number of zettel persisted.
 // In case of a failure, it returns the error and the number of zettel it has written until the error occurred.
+//
+// AddReferences appends BibTeX entries to your references.bib without changing the existing entries.
 type Repo interface {
 	GetZettel() ([]Zettel, []InconErr, error)
 	GetIndex() (Index, []InconErr, error)
 	GetBibkeys() ([]string, error)
-	Save(content map[string]string) (int, error)
+	GetReferences() ([]BibEntry, []InconErr, error)
+	GetConfig() (Config, []InconErr, error)
+	GetContexts() ([]ContextEntry, []InconErr, error)
+	Save(content map[string][]byte) (int, error)
+	AddReferences(bibtex string) error
 }
 
 // Parser handles all functionality regarding parsing from and
@@ -78,6 +152,13 @@ type Repo interface {
 type Parser interface {
 	Content(string, []Zettel) (string, error)
 	Filename(string) (Zettel, error)
+	Format(Zettel) (string, error)
 	Index(content string) (Index, []InconErr)
 	Reference(d string) []string
+	Bibliography(d string) ([]BibEntry, []InconErr)
+	Config(content string) (Config, []InconErr)
+	Contexts(content string) ([]ContextEntry, []InconErr)
+	Annotations(content, format string) ([]Annotation, error)
+	ReferenceList(content, format string) ([]BibEntry, error)
+	BibTeX(entries []BibEntry) string
 }